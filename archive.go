@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// allArchived returns the archived task IDs of every database, the ones of
+// the open database as currently archived.
+func allArchived(m Model) map[string][]int {
+	return idsByDB(m.archive, m.dbPath, m.archived)
+}
+
+// toggleArchived archives the highlighted task, or takes it out of the archive
+// in the archive view. Archived tasks are only hidden, tsk knows nothing about
+// the archive. It is saved to the state file right away like the pins.
+func toggleArchived(m Model) (Model, tea.Cmd) {
+	id, ok := highlightedTaskID(m)
+	if !ok {
+		return m, nil
+	}
+
+	archived := make(map[int]bool, len(m.archived)+1)
+	for a := range m.archived {
+		archived[a] = true
+	}
+
+	status := fmt.Sprintf("archived task %d", id)
+	if archived[id] {
+		delete(archived, id)
+		status = fmt.Sprintf("unarchived task %d", id)
+	} else {
+		archived[id] = true
+	}
+
+	m.archived = archived
+	m = refilter(m)
+
+	if m.statePath != "" {
+		if err := saveState(m.statePath, currentState(m)); err != nil {
+			return setError(m, fmt.Errorf("failed to save the archive: %w", err), nil), nil
+		}
+	}
+
+	return flashStatus(m, status)
+}
+
+// toggleArchiveView lists the archived tasks instead of the others, or goes
+// back to them.
+func toggleArchiveView(m Model) (Model, tea.Cmd) {
+	m.showArchived = !m.showArchived
+	m = refilter(m)
+
+	if m.showArchived {
+		return flashStatus(m, "showing the archived tasks")
+	}
+
+	return flashStatus(m, "showing the tasks")
+}
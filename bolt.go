@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/kakengloh/tsk/driver"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+	"github.com/kakengloh/tsk/util"
+	"go.etcd.io/bbolt"
+)
+
+// taskBucket is the bolt bucket tsk keeps the tasks in.
+var taskBucket = []byte("Task")
+
+var errUnsupported = errors.New("not supported by the task repository")
+
+// errDBLocked is returned when another program holds the lock of the
+// database.
+var errDBLocked = errors.New("the database is locked, another instance of tskui or tsk may be running")
+
+// openDB opens the bolt database at path, or the default tsk database if path
+// is empty. The returned function closes it.
+func openDB(path string) (*bbolt.DB, func(), error) {
+	if path == "" {
+		db, err := driver.NewBolt()
+		if errors.Is(err, bbolt.ErrTimeout) {
+			return nil, nil, errDBLocked
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return db, driver.CloseBolt, nil
+	}
+
+	// the same timeout as tsk, a database locked by another program fails
+	// instead of hanging
+	db, err := bbolt.Open(path, 0666, &bbolt.Options{Timeout: time.Second})
+	if errors.Is(err, bbolt.ErrTimeout) {
+		return nil, nil, errDBLocked
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return db, func() { db.Close() }, nil
+}
+
+// waitForDB opens the database like openDB, trying again for up to wait while
+// it is locked. Each try already waits a second for the lock.
+func waitForDB(path string, wait time.Duration) (*bbolt.DB, func(), error) {
+	deadline := time.Now().Add(wait)
+
+	for {
+		db, closeDB, err := openDB(path)
+		if !errors.Is(err, errDBLocked) || !time.Now().Before(deadline) {
+			return db, closeDB, err
+		}
+	}
+}
+
+// clearTaskDue removes the due date of a task. UpdateTask ignores zero values,
+// so the task is written to the bolt database directly.
+func clearTaskDue(tr repository.TaskRepository, id int) (entity.Task, error) {
+	bolt, ok := unwrapRepository(tr).(*repository.BoltTaskRepository)
+	if !ok {
+		return entity.Task{}, errUnsupported
+	}
+
+	var task entity.Task
+
+	err := bolt.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(taskBucket)
+
+		v := b.Get(util.Itob(id))
+		if v == nil {
+			return repository.ErrTaskNotFound
+		}
+
+		if err := json.Unmarshal(v, &task); err != nil {
+			return err
+		}
+
+		task.Due = time.Time{}
+
+		buf, err := json.Marshal(task)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(util.Itob(id), buf)
+	})
+
+	return task, err
+}
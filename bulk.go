@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+)
+
+// bulkStatusMsg asks the model to set the status of several tasks.
+type bulkStatusMsg struct {
+	ids    []int
+	status entity.TaskStatus
+}
+
+// bulkStepMsg is sent after the status of one task of a bulk update has been
+// set, or failed to be.
+type bulkStepMsg struct {
+	id   int
+	from entity.TaskStatus
+	err  error
+}
+
+// bulkUpdate tracks a bulk status change. The tasks are updated one after the
+// other so the progress can be shown.
+type bulkUpdate struct {
+	status   entity.TaskStatus
+	pending  []int
+	total    int
+	failed   []int
+	statuses map[int]entity.TaskStatus
+}
+
+func (b bulkUpdate) active() bool {
+	return b.total > 0
+}
+
+func (b bulkUpdate) done() int {
+	return b.total - len(b.pending)
+}
+
+func setBulkStatus(ids []int, status entity.TaskStatus) tea.Cmd {
+	return func() tea.Msg {
+		return bulkStatusMsg{ids: ids, status: status}
+	}
+}
+
+func updateOneStatus(tr repository.TaskRepository, id int, status entity.TaskStatus) tea.Cmd {
+	return func() tea.Msg {
+		for _, res := range tr.UpdateTaskStatus(status, id) {
+			if res.Err != nil {
+				return bulkStepMsg{id: id, err: res.Err}
+			}
+
+			return bulkStepMsg{id: id, from: res.FromStatus}
+		}
+
+		return bulkStepMsg{id: id, err: fmt.Errorf("task %d not updated", id)}
+	}
+}
+
+// startBulkStatus starts setting the status of the tasks, unless a bulk
+// update is already running.
+func startBulkStatus(m Model, msg bulkStatusMsg) (Model, tea.Cmd) {
+	if m.bulk.active() || len(msg.ids) == 0 {
+		return m, nil
+	}
+
+	m.bulk = bulkUpdate{
+		status:   msg.status,
+		pending:  msg.ids[1:],
+		total:    len(msg.ids),
+		statuses: map[int]entity.TaskStatus{},
+	}
+
+	return m, updateOneStatus(m.taskRepository, msg.ids[0], msg.status)
+}
+
+// bulkStep records the result of one update and starts the next one. When
+// all tasks are done the counts are reported and the tasks reloaded, if every
+// update failed the error can be retried for the same tasks.
+func bulkStep(m Model, msg bulkStepMsg) (Model, tea.Cmd) {
+	if msg.err != nil {
+		m.bulk.failed = append(m.bulk.failed, msg.id)
+	} else {
+		m.bulk.statuses[msg.id] = msg.from
+		m = recordChange(m, fmt.Sprintf("task %d → %s", msg.id, entity.TaskStatusToString[m.bulk.status]))
+	}
+
+	if len(m.bulk.pending) > 0 {
+		id := m.bulk.pending[0]
+		m.bulk.pending = m.bulk.pending[1:]
+
+		return m, updateOneStatus(m.taskRepository, id, m.bulk.status)
+	}
+
+	b := m.bulk
+	m.bulk = bulkUpdate{}
+	m.selected = nil
+
+	name := entity.TaskStatusToString[b.status]
+	succeeded := len(b.statuses)
+
+	if succeeded == 0 {
+		err := errMsg{
+			err:   fmt.Errorf("failed to mark %d tasks %s", len(b.failed), name),
+			retry: setBulkStatus(b.failed, b.status),
+		}
+		return m, func() tea.Msg { return err }
+	}
+
+	m = pushUndo(m, undoEntry{
+		description: fmt.Sprintf("marking %d tasks %s", succeeded, name),
+		statuses:    b.statuses,
+	})
+
+	status := fmt.Sprintf("marked %d tasks %s", succeeded, name)
+	if len(b.failed) > 0 {
+		status += fmt.Sprintf(", %d failed", len(b.failed))
+	}
+
+	return loadTasks(setStatus(m, status), false)
+}
+
+// bulkProgress renders the progress of the running bulk update.
+func bulkProgress(m Model) string {
+	b := m.bulk
+	label := fmt.Sprintf(" marking %d/%d tasks %s", b.done(), b.total, entity.TaskStatusToString[b.status])
+
+	return m.progress.ViewAs(float64(b.done())/float64(b.total)) + statusStyle.Render(label)
+}
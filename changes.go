@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// changeTimeFormat formats the times of the change log.
+const changeTimeFormat = "2006-01-02 15:04"
+
+// changeRecord is a change made to the tasks in this session.
+type changeRecord struct {
+	at   time.Time
+	text string
+}
+
+// recordChange appends a change to the log of the session, empty changes are
+// ignored.
+func recordChange(m Model, text string) Model {
+	if text == "" {
+		return m
+	}
+
+	m.changes = append(m.changes, changeRecord{at: time.Now(), text: text})
+
+	return m
+}
+
+// renderChangeLog renders the changes of the session, the latest ones if they
+// don't all fit height.
+func renderChangeLog(changes []changeRecord, height int, closeKeys string) string {
+	b := strings.Builder{}
+
+	b.WriteString(helpTitleStyle.Render("Changes this session"))
+	b.WriteString("\n\n")
+
+	if len(changes) == 0 {
+		b.WriteString(helpDescStyle.Render("No changes yet."))
+		b.WriteString("\n")
+	}
+
+	// the title, the blank lines and the hint take four lines
+	if lines := height - 4; height > 0 && len(changes) > lines && lines > 0 {
+		changes = changes[len(changes)-lines:]
+	}
+
+	for _, change := range changes {
+		b.WriteString(helpKeyStyle.Render(change.at.Format("15:04:05")))
+		b.WriteString("  ")
+		b.WriteString(helpDescStyle.Render(change.text))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpHintStyle.Render(closeKeys + ": close"))
+
+	return b.String()
+}
+
+// appendChangeLog appends the changes to the file at path, it is created if
+// it doesn't exist.
+func appendChangeLog(path string, changes []changeRecord) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if _, err := fmt.Fprintf(f, "%s  %s\n", change.at.Format(changeTimeFormat), change.text); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	return f.Close()
+}
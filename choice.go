@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+)
+
+// choiceDoneMsg is sent when the choice picker is closed, action is the
+// command of the picked option or nil if it was cancelled.
+type choiceDoneMsg struct {
+	action tea.Cmd
+}
+
+// choicePicker lists options to pick one of, with the cursor or by its
+// number.
+type choicePicker struct {
+	title   string
+	options []string
+	cursor  int
+	pick    func(i int) tea.Cmd
+}
+
+func newChoicePicker(title string, options []string, cursor int, pick func(i int) tea.Cmd) choicePicker {
+	return choicePicker{title: title, options: options, cursor: cursor, pick: pick}
+}
+
+func (p choicePicker) done(action tea.Cmd) tea.Cmd {
+	return func() tea.Msg { return choiceDoneMsg{action: action} }
+}
+
+func (p choicePicker) Update(msg tea.Msg) (choicePicker, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch k := keyMsg.String(); k {
+	case "esc", "q":
+		return p, p.done(nil)
+
+	case "enter", " ":
+		return p, p.done(p.pick(p.cursor))
+
+	case "j", "down":
+		if p.cursor < len(p.options)-1 {
+			p.cursor++
+		}
+
+	case "k", "up":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+
+	default:
+		if len(k) == 1 && k[0] >= '1' && int(k[0]-'1') < len(p.options) {
+			return p, p.done(p.pick(int(k[0] - '1')))
+		}
+	}
+
+	return p, nil
+}
+
+func (p choicePicker) View() string {
+	b := strings.Builder{}
+
+	b.WriteString(promptLabelStyle.Render(p.title))
+	b.WriteString("\n")
+
+	for i, option := range p.options {
+		cursor := "  "
+		if i == p.cursor {
+			cursor = "> "
+		}
+
+		line := fmt.Sprintf("%s%d %s", cursor, i+1, option)
+		if i == p.cursor {
+			line = helpKeyStyle.Render(line)
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpHintStyle.Render("enter: pick · esc: cancel"))
+
+	return b.String()
+}
+
+// priorityOptions lists the priorities a task can be given, lowest first.
+var priorityOptions = []entity.TaskPriority{entity.TaskPriorityLow, entity.TaskPriorityMedium, entity.TaskPriorityHigh}
+
+// newPriorityPicker picks the priority of the task, starting at its current
+// one.
+func newPriorityPicker(tr repository.TaskRepository, task entity.Task) choicePicker {
+	names := make([]string, len(priorityOptions))
+	cursor := 0
+
+	for i, priority := range priorityOptions {
+		names[i] = entity.TaskPriorityToString[priority]
+		if priority == task.Priority {
+			cursor = i
+		}
+	}
+
+	return newChoicePicker(fmt.Sprintf("Priority of task %d", task.ID), names, cursor, func(i int) tea.Cmd {
+		return setTaskPriority(tr, task.ID, priorityOptions[i])
+	})
+}
+
+// statusOptions lists the statuses a task can have, in their order of
+// progress.
+var statusOptions = []entity.TaskStatus{entity.TaskStatusTodo, entity.TaskStatusDoing, entity.TaskStatusDone}
+
+// newStatusPicker picks the status of the task, starting at its current one.
+func newStatusPicker(tr repository.TaskRepository, task entity.Task) choicePicker {
+	names := make([]string, len(statusOptions))
+	cursor := 0
+
+	for i, status := range statusOptions {
+		names[i] = entity.TaskStatusToString[status]
+		if status == task.Status {
+			cursor = i
+		}
+	}
+
+	return newChoicePicker(fmt.Sprintf("Status of task %d", task.ID), names, cursor, func(i int) tea.Cmd {
+		return setTaskStatus(tr, task, statusOptions[i])
+	})
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+)
+
+// doneTasksListedMsg delivers the done tasks of the database, the ones
+// clearing the done tasks would delete.
+type doneTasksListedMsg struct {
+	tasks []entity.Task
+}
+
+// doneTasksClearedMsg is sent after the done tasks have been deleted, failed
+// holds the ones that could not be.
+type doneTasksClearedMsg struct {
+	deleted []entity.Task
+	failed  []entity.Task
+}
+
+// listDoneTasks lists every done task, whatever the filters of the table.
+func listDoneTasks(tr repository.TaskRepository) tea.Cmd {
+	return retryable(func() tea.Msg {
+		tasks, err := tr.ListTasksWithFilters(entity.TaskFilters{Status: entity.TaskStatusDone})
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to list the done tasks: %w", err)}
+		}
+
+		return doneTasksListedMsg{tasks: tasks}
+	})
+}
+
+// clearDoneTasks deletes the tasks one by one, a failing task doesn't stop
+// the others from being deleted.
+func clearDoneTasks(tr repository.TaskRepository, tasks []entity.Task) tea.Cmd {
+	return func() tea.Msg {
+		msg := doneTasksClearedMsg{}
+
+		for _, task := range tasks {
+			if err := tr.DeleteTask(task.ID); err != nil {
+				msg.failed = append(msg.failed, task)
+				continue
+			}
+
+			msg.deleted = append(msg.deleted, task)
+		}
+
+		return msg
+	}
+}
+
+// confirmClearDone asks before deleting the listed done tasks. The answer is
+// only asked from the table, the user may have moved on meanwhile.
+func confirmClearDone(m Model, msg doneTasksListedMsg) (Model, tea.Cmd) {
+	if m.mode != modeTable {
+		return m, nil
+	}
+
+	if len(msg.tasks) == 0 {
+		return flashStatus(m, "no done tasks to delete")
+	}
+
+	m.mode = modeConfirm
+	m.confirm = newConfirmDialog(fmt.Sprintf("Delete all %d done tasks?", len(msg.tasks)), clearDoneTasks(m.taskRepository, msg.tasks))
+
+	return m, nil
+}
+
+// doneTasksCleared reports the deleted tasks and lists the tasks again. If
+// every deletion failed the error can be retried for the same tasks.
+func doneTasksCleared(m Model, msg doneTasksClearedMsg) (Model, tea.Cmd) {
+	if len(msg.deleted) == 0 {
+		err := errMsg{
+			err:   fmt.Errorf("failed to delete %d done tasks", len(msg.failed)),
+			retry: clearDoneTasks(m.taskRepository, msg.failed),
+		}
+		return m, func() tea.Msg { return err }
+	}
+
+	m = recordChange(m, fmt.Sprintf("%d done tasks deleted", len(msg.deleted)))
+	m = pushUndo(m, undoEntry{
+		description: fmt.Sprintf("delete of %d done tasks", len(msg.deleted)),
+		deleted:     msg.deleted,
+	})
+
+	status := fmt.Sprintf("deleted %d done tasks", len(msg.deleted))
+	if len(msg.failed) > 0 {
+		status += fmt.Sprintf(", %d failed", len(msg.failed))
+	}
+
+	return loadTasks(setStatus(m, status), false)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/entity"
+)
+
+// taskCopiedMsg is sent when a task has been copied to the clipboard.
+type taskCopiedMsg struct {
+	id int
+}
+
+// copyFailedMsg is sent when the clipboard could not be written, the text is
+// printed on quit instead.
+type copyFailedMsg struct {
+	id   int
+	text string
+	err  error
+}
+
+// taskClipboardText formats the task to be pasted, e.g. into a chat:
+//
+//	#12 write docs
+//	status: todo · priority: high · due: 2022-10-01 12:00
+//	- first note
+func taskClipboardText(task entity.Task, format string) string {
+	b := strings.Builder{}
+
+	fmt.Fprintf(&b, "#%d %s\n", task.ID, singleLine(task.Title))
+	fmt.Fprintf(&b, "status: %s · priority: %s", entity.TaskStatusToString[task.Status], entity.TaskPriorityToString[task.Priority])
+	if !task.Due.IsZero() {
+		fmt.Fprintf(&b, " · due: %s", task.Due.Format(format))
+	}
+	b.WriteString("\n")
+
+	for _, note := range task.Notes {
+		b.WriteString("- ")
+		b.WriteString(strings.ReplaceAll(note, "\n", "\n  "))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func copyTask(task entity.Task, format string) tea.Cmd {
+	text := taskClipboardText(task, format)
+
+	return func() tea.Msg {
+		if err := clipboard.WriteAll(text); err != nil {
+			return copyFailedMsg{id: task.ID, text: text, err: err}
+		}
+
+		return taskCopiedMsg{id: task.ID}
+	}
+}
+
+// copyFailed keeps the text to print it on quit, and tells so.
+func copyFailed(m Model, msg copyFailedMsg) Model {
+	m.uncopied = append(m.uncopied, msg.text)
+
+	return setError(m, fmt.Errorf("failed to copy task %d, it is printed on quit: %w", msg.id, msg.err), nil)
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/evertras/bubble-table/table"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	minColumnWidth = 3
+	minFlexWidth   = 10
+
+	// selectColumnWidth is the width of the selection checkbox column the
+	// table adds in front, including its divider.
+	selectColumnWidth = 4
+)
+
+var (
+	columnOrder = []string{
+		columnKeyIcon,
+		columnKeyID,
+		columnKeyTitle,
+		columnKeyStatus,
+		columnKeyPriority,
+		columnKeyCreated,
+		columnKeyUpdated,
+		columnKeyDueDate,
+		columnKeyDueIn,
+		columnKeyNotes,
+	}
+
+	columnTitles = map[string]string{
+		columnKeyIcon:     "",
+		columnKeyID:       "ID",
+		columnKeyTitle:    "Title",
+		columnKeyStatus:   "Status",
+		columnKeyPriority: "Priority",
+		columnKeyCreated:  "Created",
+		columnKeyUpdated:  "Updated",
+		columnKeyDueDate:  "Due Date",
+		columnKeyDueIn:    "Due In",
+		columnKeyNotes:    "Notes",
+	}
+
+	// sortColumns lists the columns the table can be sorted by, in the order
+	// they are cycled through.
+	sortColumns = []string{
+		columnKeyID,
+		columnKeyTitle,
+		columnKeyStatus,
+		columnKeyPriority,
+		columnKeyCreated,
+		columnKeyUpdated,
+		columnKeyDueDate,
+	}
+
+	// defaultColumnWidths holds the widths of the fixed columns, the flex
+	// columns share the remaining space.
+	defaultColumnWidths = map[string]int{
+		columnKeyIcon:     3,
+		columnKeyID:       5,
+		columnKeyStatus:   6,
+		columnKeyPriority: 8,
+		columnKeyCreated:  19,
+		columnKeyUpdated:  19,
+		columnKeyDueDate:  19,
+		columnKeyDueIn:    10,
+		columnKeyNotes:    6,
+	}
+
+	// flexColumns holds the share of the remaining space the flex columns
+	// get, so hiding a fixed column makes room for the title.
+	flexColumns = map[string]int{
+		columnKeyTitle: 1,
+	}
+)
+
+// fitColumnWidths shrinks the fixed columns proportionally when they don't fit
+// into the terminal width next to the given number of columns. A zero width
+// means unknown, no shrinking is done.
+func fitColumnWidths(widths map[string]int, width int, columns int) map[string]int {
+	fitted := make(map[string]int, len(widths))
+	needed := 0
+
+	for key, w := range widths {
+		fitted[key] = w
+		needed += w
+	}
+
+	available := width - columns - 1 - minFlexWidth - selectColumnWidth
+	if width == 0 || needed <= available {
+		return fitted
+	}
+
+	for key, w := range widths {
+		fitted[key] = w * available / needed
+		if fitted[key] < minColumnWidth {
+			fitted[key] = minColumnWidth
+		}
+	}
+
+	return fitted
+}
+
+// columnLayout returns the keys of the visible columns and the widths of the
+// fixed ones among them, fitted into width.
+func columnLayout(width int, widths map[string]int, hidden map[string]bool) ([]string, map[string]int) {
+	visible := []string{}
+	fixed := map[string]int{}
+
+	for _, key := range columnOrder {
+		if hidden[key] {
+			continue
+		}
+
+		visible = append(visible, key)
+		if w, ok := widths[key]; ok {
+			fixed[key] = w
+		}
+	}
+
+	return visible, fitColumnWidths(fixed, width, len(visible))
+}
+
+// buildColumns returns the visible columns, fitted into width. The columns
+// given a width in widths are fixed, the others share the remaining space.
+func buildColumns(width int, widths map[string]int, hidden map[string]bool) []table.Column {
+	visible, fitted := columnLayout(width, widths, hidden)
+	columns := []table.Column{}
+
+	for _, key := range visible {
+		w, ok := fitted[key]
+		if !ok {
+			columns = append(columns, table.NewFlexColumn(key, columnTitles[key], flexColumns[key]))
+			continue
+		}
+
+		column := table.NewColumn(key, columnTitles[key], w)
+
+		if key == columnKeyID {
+			column = column.WithStyle(idColumnStyle)
+		}
+
+		columns = append(columns, column)
+	}
+
+	return columns
+}
+
+// titleColumnWidth returns the width the title column gets in a table of the
+// given width, the way the table shares the space left by the fixed columns
+// among the flex ones. It is zero if the width is unknown or the title hidden.
+func titleColumnWidth(width int, widths map[string]int, hidden map[string]bool) int {
+	if width == 0 || hidden[columnKeyTitle] {
+		return 0
+	}
+
+	visible, fitted := columnLayout(width, widths, hidden)
+	if w, ok := fitted[columnKeyTitle]; ok {
+		return w
+	}
+
+	// the borders, one between each column and one on both sides
+	available := width - len(visible) - 1 - selectColumnWidth
+	factors := 0
+	for _, key := range visible {
+		if w, ok := fitted[key]; ok {
+			available -= w
+		} else {
+			factors += flexColumns[key]
+		}
+	}
+
+	if factors == 0 || available < 1 {
+		return 1
+	}
+
+	return available * flexColumns[columnKeyTitle] / factors
+}
+
+// ellipsize fits s into width cells, ending it with an ellipsis when it is
+// cut. Wide runes are kept whole, a zero width leaves s as it is.
+func ellipsize(s string, width int) string {
+	if width <= 0 || runewidth.StringWidth(s) <= width {
+		return s
+	}
+
+	return runewidth.Truncate(s, width, "…")
+}
+
+// singleLine joins the lines of s with spaces, leaving out the blank ones.
+func singleLine(s string) string {
+	lines := []string{}
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, " ")
+}
+
+// glyphSet holds the characters of the icon column, one for the priority and
+// one for the status of a task.
+type glyphSet struct {
+	priorities map[entity.TaskPriority]string
+	statuses   map[entity.TaskStatus]string
+}
+
+var (
+	unicodeGlyphs = glyphSet{
+		priorities: map[entity.TaskPriority]string{
+			entity.TaskPriorityLow:    "·",
+			entity.TaskPriorityMedium: "•",
+			entity.TaskPriorityHigh:   "●",
+		},
+		statuses: map[entity.TaskStatus]string{
+			entity.TaskStatusTodo:  "□",
+			entity.TaskStatusDoing: "▶",
+			entity.TaskStatusDone:  "✓",
+		},
+	}
+
+	// asciiGlyphs are used when the config file turns the glyphs off, for
+	// terminals and fonts without them
+	asciiGlyphs = glyphSet{
+		priorities: map[entity.TaskPriority]string{
+			entity.TaskPriorityLow:    ".",
+			entity.TaskPriorityMedium: "o",
+			entity.TaskPriorityHigh:   "O",
+		},
+		statuses: map[entity.TaskStatus]string{
+			entity.TaskStatusTodo:  "-",
+			entity.TaskStatusDoing: ">",
+			entity.TaskStatusDone:  "x",
+		},
+	}
+)
+
+// iconCell renders the priority and the status of the task as glyphs in
+// their colors, e.g. "● ✓" for a done high priority task.
+func iconCell(task entity.Task, glyphs glyphSet) string {
+	priority := glyphs.priorities[task.Priority]
+	if style, ok := priorityStyles[task.Priority]; ok {
+		priority = style.Render(priority)
+	}
+
+	status := glyphs.statuses[task.Status]
+	if style, ok := statusStyles[task.Status]; ok {
+		status = style.Render(status)
+	}
+
+	return priority + " " + status
+}
+
+// priorityCell renders the priority in the color of its level. Only the
+// foreground is set, so the highlight background still shows.
+func priorityCell(priority entity.TaskPriority) interface{} {
+	name := entity.TaskPriorityToString[priority]
+
+	if style, ok := priorityStyles[priority]; ok {
+		return table.NewStyledCell(name, style)
+	}
+
+	return name
+}
+
+// statusCell renders the status in its own color.
+func statusCell(status entity.TaskStatus) interface{} {
+	name := entity.TaskStatusToString[status]
+
+	if style, ok := statusStyles[status]; ok {
+		return table.NewStyledCell(name, style)
+	}
+
+	return name
+}
+
+func validSortColumn(column string) bool {
+	for _, c := range sortColumns {
+		if c == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validColumnWidths returns the default column widths overridden by the valid
+// ones of widths, unknown columns and widths below one are reported and
+// ignored. A width given for a flex column makes it fixed.
+func validColumnWidths(widths map[string]int) map[string]int {
+	valid := make(map[string]int, len(defaultColumnWidths))
+	for key, w := range defaultColumnWidths {
+		valid[key] = w
+	}
+
+	for key, w := range widths {
+		if _, ok := columnTitles[key]; !ok {
+			log.Printf("unknown column %q in the column widths, ignoring it", key)
+			continue
+		}
+		if w < 1 {
+			log.Printf("invalid width %d for column %q, using the default", w, key)
+			continue
+		}
+		valid[key] = w
+	}
+
+	return valid
+}
+
+// changedColumnWidths returns the widths differing from the defaults, the
+// ones saved to the config file.
+func changedColumnWidths(widths map[string]int) map[string]int {
+	changed := map[string]int{}
+	for key, w := range widths {
+		if w != defaultColumnWidths[key] {
+			changed[key] = w
+		}
+	}
+
+	return changed
+}
+
+// validColumns drops the unknown column keys, reporting them.
+func validColumns(keys []string) map[string]bool {
+	valid := map[string]bool{}
+
+	for _, key := range keys {
+		if _, ok := columnTitles[key]; !ok {
+			log.Printf("unknown column %q, ignoring it", key)
+			continue
+		}
+		valid[key] = true
+	}
+
+	return valid
+}
+
+// dueCell renders the due date, in the warning color if the task is due soon.
+func dueCell(task entity.Task, format string, soon, relative time.Duration) interface{} {
+	due := taskDueAsString(task, format, relative)
+
+	if isDueSoon(task, soon) {
+		return table.NewStyledCell(dueSoonPrefix+due, dueSoonStyle)
+	}
+
+	return due
+}
+
+// compactDuration formats d in its largest whole unit: 3d, 5h or 20m.
+func compactDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+
+	return fmt.Sprintf("%dm", int(d/time.Minute))
+}
+
+// dueInCell renders the time left until the due date, like "in 3d" or
+// "2d ago", colored like the due date.
+func dueInCell(task entity.Task, soon time.Duration) interface{} {
+	if task.Due.IsZero() {
+		return ""
+	}
+
+	until := time.Until(task.Due)
+	if until < 0 {
+		ago := compactDuration(-until) + " ago"
+		if isOverdue(task) {
+			return table.NewStyledCell(overduePrefix+ago, overdueRowStyle)
+		}
+		return ago
+	}
+
+	in := "in " + compactDuration(until)
+	if isDueSoon(task, soon) {
+		return table.NewStyledCell(dueSoonPrefix+in, dueSoonStyle)
+	}
+
+	return in
+}
+
+// sortKey returns the hidden row data key holding the sortable value of a
+// column, so that e.g. priorities are sorted by level, not alphabetically.
+func sortKey(column string) string {
+	return "sort_" + column
+}
+
+func taskSortValues(task entity.Task) table.RowData {
+	due := int64(math.MaxInt64)
+	if !task.Due.IsZero() {
+		due = task.Due.Unix()
+	}
+
+	return table.RowData{
+		sortKey(columnKeyID):       task.ID,
+		sortKey(columnKeyTitle):    strings.ToLower(task.Title),
+		sortKey(columnKeyStatus):   int(task.Status),
+		sortKey(columnKeyPriority): int(task.Priority),
+		sortKey(columnKeyCreated):  task.CreatedAt.Unix(),
+		sortKey(columnKeyUpdated):  task.UpdatedAt.Unix(),
+		sortKey(columnKeyDueDate):  due,
+	}
+}
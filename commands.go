@@ -0,0 +1,351 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+)
+
+// errMsg is sent when a repository operation fails. If retry is set, it
+// re-runs the failed operation.
+type errMsg struct {
+	err   error
+	retry tea.Cmd
+}
+
+// jumpToTaskMsg asks the model to highlight a listed task.
+type jumpToTaskMsg struct {
+	id int
+}
+
+// reloadMsg asks the model to list the tasks again.
+type reloadMsg struct{}
+
+// taskDeletedMsg is sent after a task has been deleted.
+type taskDeletedMsg struct {
+	id   int
+	undo undoEntry
+}
+
+// tasksDeletedMsg is sent after the selected tasks have been deleted.
+type tasksDeletedMsg struct {
+	count int
+	undo  undoEntry
+}
+
+// taskCreatedMsg is sent after a task has been created.
+type taskCreatedMsg struct {
+	task entity.Task
+}
+
+// taskUpdatedMsg is sent after a task has been updated, undo is set if the
+// update can be undone. change describes the update for the change log, it is
+// empty if nothing changed.
+type taskUpdatedMsg struct {
+	task   entity.Task
+	undo   undoEntry
+	change string
+}
+
+// searchMsg is sent when the title search query changes.
+type searchMsg struct {
+	query string
+}
+
+// retryable makes a failing cmd retryable by attaching itself to the errMsg
+// it returns.
+func retryable(cmd tea.Cmd) tea.Cmd {
+	var wrapped tea.Cmd
+
+	wrapped = func() tea.Msg {
+		msg := cmd()
+		if msg, ok := msg.(errMsg); ok && msg.retry == nil {
+			msg.retry = wrapped
+			return msg
+		}
+
+		return msg
+	}
+
+	return wrapped
+}
+
+func reloadTasks() tea.Msg {
+	return reloadMsg{}
+}
+
+// quickAddTask creates a todo task with the default priority from a title.
+func quickAddTask(tr repository.TaskRepository) func(string) (tea.Cmd, error) {
+	return func(value string) (tea.Cmd, error) {
+		title := strings.TrimSpace(value)
+		if title == "" {
+			return nil, fmt.Errorf("title must not be empty")
+		}
+
+		return createTask(tr, title, entity.TaskPriorityLow, time.Time{}, ""), nil
+	}
+}
+
+// createTask creates a todo task, from the form or quick-added.
+func createTask(tr repository.TaskRepository, title string, priority entity.TaskPriority, due time.Time, note string) tea.Cmd {
+	return retryable(func() tea.Msg {
+		task, err := tr.CreateTask(title, priority, entity.TaskStatusTodo, due, note)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to create task: %w", err)}
+		}
+
+		return taskCreatedMsg{task: task}
+	})
+}
+
+func deleteTask(tr repository.TaskRepository, id int) tea.Cmd {
+	return retryable(func() tea.Msg {
+		task, err := tr.GetTaskByID(id)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to delete task %d: %w", id, err)}
+		}
+
+		if err := tr.DeleteTask(id); err != nil {
+			return errMsg{err: fmt.Errorf("failed to delete task %d: %w", id, err)}
+		}
+
+		return taskDeletedMsg{
+			id: id,
+			undo: undoEntry{
+				description: fmt.Sprintf("delete of task %d", id),
+				deleted:     []entity.Task{task},
+			},
+		}
+	})
+}
+
+func deleteTasks(tr repository.TaskRepository, ids []int) tea.Cmd {
+	return retryable(func() tea.Msg {
+		tasks := make([]entity.Task, len(ids))
+		for i, id := range ids {
+			task, err := tr.GetTaskByID(id)
+			if err != nil {
+				return errMsg{err: fmt.Errorf("failed to delete task %d: %w", id, err)}
+			}
+			tasks[i] = task
+		}
+
+		if err := tr.DeleteTask(ids...); err != nil {
+			return errMsg{err: fmt.Errorf("failed to delete %d tasks: %w", len(ids), err)}
+		}
+
+		return tasksDeletedMsg{
+			count: len(ids),
+			undo: undoEntry{
+				description: fmt.Sprintf("delete of %d tasks", len(ids)),
+				deleted:     tasks,
+			},
+		}
+	})
+}
+
+func nextTaskStatus(status entity.TaskStatus) entity.TaskStatus {
+	if status >= entity.TaskStatusDone {
+		return entity.TaskStatusTodo
+	}
+
+	return status + 1
+}
+
+func advanceTaskStatus(tr repository.TaskRepository, id int) tea.Cmd {
+	return retryable(func() tea.Msg {
+		task, err := tr.GetTaskByID(id)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to update task %d: %w", id, err)}
+		}
+
+		return updateStatus(tr, id, nextTaskStatus(task.Status))
+	})
+}
+
+// setTaskStatus gives the task the status picked for it.
+func setTaskStatus(tr repository.TaskRepository, task entity.Task, status entity.TaskStatus) tea.Cmd {
+	if status == task.Status {
+		return func() tea.Msg { return taskUpdatedMsg{task: task} }
+	}
+
+	return retryable(func() tea.Msg {
+		return updateStatus(tr, task.ID, status)
+	})
+}
+
+// updateStatus saves the status of the task, the previous one can be
+// restored with undo.
+func updateStatus(tr repository.TaskRepository, id int, status entity.TaskStatus) tea.Msg {
+	res := tr.UpdateTaskStatus(status, id)
+	if len(res) == 0 {
+		return errMsg{err: fmt.Errorf("failed to update task %d: %w", id, repository.ErrTaskNotFound)}
+	}
+	if res[0].Err != nil {
+		return errMsg{err: fmt.Errorf("failed to update task %d: %w", id, res[0].Err)}
+	}
+
+	return taskUpdatedMsg{
+		task: res[0].Task,
+		undo: undoEntry{
+			description: fmt.Sprintf("status change of task %d", id),
+			statuses:    map[int]entity.TaskStatus{id: res[0].FromStatus},
+		},
+		change: fmt.Sprintf("task %d → %s", id, entity.TaskStatusToString[res[0].Task.Status]),
+	}
+}
+
+func shiftTaskPriority(tr repository.TaskRepository, id int, delta int) tea.Cmd {
+	return retryable(func() tea.Msg {
+		task, err := tr.GetTaskByID(id)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to update task %d: %w", id, err)}
+		}
+
+		// lowering no priority would raise it to low, and tsk can't save no
+		// priority anyway
+		if task.Priority == entity.TaskPriorityNone && delta < 0 {
+			return taskUpdatedMsg{task: task}
+		}
+
+		priority := task.Priority + entity.TaskPriority(delta)
+		if priority < entity.TaskPriorityLow {
+			priority = entity.TaskPriorityLow
+		}
+		if priority > entity.TaskPriorityHigh {
+			priority = entity.TaskPriorityHigh
+		}
+
+		return updatePriority(tr, task, priority)
+	})
+}
+
+// setTaskPriority gives the task the priority picked for it.
+func setTaskPriority(tr repository.TaskRepository, id int, priority entity.TaskPriority) tea.Cmd {
+	return retryable(func() tea.Msg {
+		task, err := tr.GetTaskByID(id)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to update task %d: %w", id, err)}
+		}
+
+		return updatePriority(tr, task, priority)
+	})
+}
+
+// updatePriority saves the priority of the task unless it already has it.
+func updatePriority(tr repository.TaskRepository, task entity.Task, priority entity.TaskPriority) tea.Msg {
+	if priority == task.Priority {
+		return taskUpdatedMsg{task: task}
+	}
+
+	updated, err := tr.UpdateTask(task.ID, entity.Task{Priority: priority})
+	if err != nil {
+		return errMsg{err: fmt.Errorf("failed to update task %d: %w", task.ID, err)}
+	}
+
+	return taskUpdatedMsg{
+		task:   updated,
+		change: fmt.Sprintf("task %d priority → %s", task.ID, entity.TaskPriorityToString[priority]),
+	}
+}
+
+// snoozeTask pushes the due date of the task by d, a task without a due date
+// becomes due d from now.
+func snoozeTask(tr repository.TaskRepository, id int, d time.Duration) tea.Cmd {
+	return retryable(func() tea.Msg {
+		task, err := tr.GetTaskByID(id)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to update task %d: %w", id, err)}
+		}
+
+		due := task.Due
+		if due.IsZero() {
+			due = time.Now()
+		}
+
+		task, err = tr.UpdateTask(id, entity.Task{Due: due.Add(d)})
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to update task %d: %w", id, err)}
+		}
+
+		return taskUpdatedMsg{
+			task:   task,
+			change: fmt.Sprintf("task %d due → %s", id, task.Due.Format(changeTimeFormat)),
+		}
+	})
+}
+
+func clearDue(tr repository.TaskRepository, id int) tea.Cmd {
+	return retryable(func() tea.Msg {
+		task, err := clearTaskDue(tr, id)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("failed to clear the due date of task %d: %w", id, err)}
+		}
+
+		return taskUpdatedMsg{task: task, change: fmt.Sprintf("task %d due date removed", id)}
+	})
+}
+
+func renameTask(tr repository.TaskRepository, id int) func(string) (tea.Cmd, error) {
+	return func(value string) (tea.Cmd, error) {
+		title := strings.TrimSpace(value)
+		if title == "" {
+			return nil, fmt.Errorf("title must not be empty")
+		}
+
+		return retryable(func() tea.Msg {
+			task, err := tr.UpdateTask(id, entity.Task{Title: title})
+			if err != nil {
+				return errMsg{err: fmt.Errorf("failed to update task %d: %w", id, err)}
+			}
+
+			return taskUpdatedMsg{task: task, change: fmt.Sprintf("task %d renamed to %q", id, title)}
+		}), nil
+	}
+}
+
+func addNote(tr repository.TaskRepository, id int) func(string) (tea.Cmd, error) {
+	return func(value string) (tea.Cmd, error) {
+		note := strings.TrimSpace(value)
+		if note == "" {
+			return nil, fmt.Errorf("note must not be empty")
+		}
+
+		return retryable(func() tea.Msg {
+			task, err := tr.AddNotes(id, note)
+			if err != nil {
+				return errMsg{err: fmt.Errorf("failed to add a note to task %d: %w", id, err)}
+			}
+
+			return taskUpdatedMsg{task: task, change: fmt.Sprintf("note added to task %d", id)}
+		}), nil
+	}
+}
+
+func jumpToTask(tasks []entity.Task) func(string) (tea.Cmd, error) {
+	return func(value string) (tea.Cmd, error) {
+		id, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a task ID", value)
+		}
+
+		for _, task := range tasks {
+			if task.ID == id {
+				return func() tea.Msg { return jumpToTaskMsg{id: id} }, nil
+			}
+		}
+
+		return nil, fmt.Errorf("task %d is not listed", id)
+	}
+}
+
+func searchTasks(query string) tea.Cmd {
+	return func() tea.Msg {
+		return searchMsg{query: query}
+	}
+}
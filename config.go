@@ -0,0 +1,388 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/evertras/bubble-table/table"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/xeonx/timeago"
+)
+
+const (
+	defaultTimeFormat      = "2006-01-02 15:04:05"
+	defaultRefreshInterval = 2 * time.Second
+	defaultDueSoon         = 72 * time.Hour
+	defaultRelativeDue     = 24 * time.Hour
+)
+
+type config struct {
+	TimeFormat      string
+	TimeLocale      timeago.Config // the language of the relative times
+	RefreshInterval time.Duration
+	DueSoon         time.Duration
+	RelativeDue     time.Duration
+	NotifyOverdue   bool
+	RefreshOnFocus  bool   // reload the tasks when the terminal gets the focus
+	ASCIIGlyphs     bool   // the icon column uses ASCII characters
+	StartupCommand  string // run with the shell before the database is opened
+	ReadOnly        bool
+	DBPath          string
+	DBName          string // the --db profile or path, empty for the default database
+	Profiles        map[string]string
+	StatePath       string
+	ConfigPath      string
+	HiddenColumns   []string
+	ColumnWidths    map[string]int
+	PageSize        int // zero derives the page size from the terminal height
+	Filters         entity.TaskFilters
+	SortColumn      string // empty unless the config file sets a default sort
+	SortDesc        bool
+	TiebreakColumn  string // orders the tasks equal by the sort column
+	TiebreakDesc    bool
+	Theme           Theme
+	ThemePreset     string
+	Keys            keyMap
+	TableKeys       table.KeyMap
+}
+
+func defaultConfig() config {
+	return config{
+		TimeFormat:      defaultTimeFormat,
+		TimeLocale:      timeago.English,
+		RefreshInterval: defaultRefreshInterval,
+		DueSoon:         defaultDueSoon,
+		RelativeDue:     defaultRelativeDue,
+		RefreshOnFocus:  true,
+		Theme:           defaultTheme(),
+		ThemePreset:     defaultThemePreset,
+		HiddenColumns:   []string{columnKeyUpdated, columnKeyDueIn},
+		Keys:            defaultKeyMap(),
+		TableKeys:       defaultTableKeyMap(),
+	}
+}
+
+// fileConfig is the content of the configuration file, missing values keep
+// their defaults.
+type fileConfig struct {
+	ThemePreset    string            `json:"theme_preset"`
+	Theme          Theme             `json:"theme"`
+	Keys           keyConfig         `json:"keys"`
+	HiddenColumns  []string          `json:"hidden_columns"`
+	ColumnWidths   map[string]int    `json:"column_widths"`
+	PageSize       int               `json:"page_size"`
+	DueSoon        string            `json:"due_soon"`
+	RelativeDue    string            `json:"relative_due"`
+	NotifyOverdue  bool              `json:"notify_overdue"`
+	RefreshOnFocus *bool             `json:"refresh_on_focus"`
+	StartupCommand string            `json:"startup_command"`
+	TimeLocale     string            `json:"time_locale"`
+	Glyphs         *bool             `json:"glyphs"`
+	Profiles       map[string]string `json:"profiles"`
+	DefaultFilter  struct {
+		Status   string `json:"status"`
+		Priority string `json:"priority"`
+	} `json:"default_filter"`
+	DefaultSort struct {
+		Column     string `json:"column"`
+		Descending bool   `json:"descending"`
+	} `json:"default_sort"`
+	SortTiebreaker struct {
+		Column     string `json:"column"`
+		Descending bool   `json:"descending"`
+	} `json:"sort_tiebreaker"`
+}
+
+// configFilePath returns the path of the configuration file, TSKUI_CONFIG
+// overrides the default location in the user's config directory.
+func configFilePath() (string, error) {
+	if path := os.Getenv("TSKUI_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "tskui", "config.json"), nil
+}
+
+// readConfigFile reads the configuration file into cfg, a missing file is not
+// an error.
+func readConfigFile(path string, cfg *config) error {
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// the colors of the theme are set over the ones of the preset
+	preset := cfg.Theme
+	if err := json.Unmarshal(buf, &struct {
+		ThemePreset *string `json:"theme_preset"`
+	}{&cfg.ThemePreset}); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if t, ok := themePreset(cfg.ThemePreset); ok {
+		preset = t
+	} else {
+		log.Printf("unknown theme preset %q, using %q", cfg.ThemePreset, defaultThemePreset)
+		cfg.ThemePreset = defaultThemePreset
+	}
+
+	file := fileConfig{Theme: preset, HiddenColumns: cfg.HiddenColumns}
+	if err := json.Unmarshal(buf, &file); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	cfg.Theme = validateTheme(file.Theme, preset)
+	cfg.HiddenColumns = file.HiddenColumns
+	cfg.ColumnWidths = file.ColumnWidths
+	cfg.Profiles = file.Profiles
+	cfg.NotifyOverdue = file.NotifyOverdue
+	if file.DueSoon != "" {
+		cfg.DueSoon = parseThreshold("due soon", file.DueSoon, cfg.DueSoon)
+	}
+	if file.RelativeDue != "" {
+		cfg.RelativeDue = parseThreshold("relative due", file.RelativeDue, cfg.RelativeDue)
+	}
+	if file.RefreshOnFocus != nil {
+		cfg.RefreshOnFocus = *file.RefreshOnFocus
+	}
+	cfg.StartupCommand = file.StartupCommand
+	if file.TimeLocale != "" {
+		if c, ok := timeLocale(file.TimeLocale); ok {
+			cfg.TimeLocale = c
+		} else {
+			log.Printf("unknown time locale %q, using english", file.TimeLocale)
+		}
+	}
+	cfg.ASCIIGlyphs = file.Glyphs != nil && !*file.Glyphs
+	if file.PageSize < 0 {
+		log.Printf("invalid page size %d, using the terminal height", file.PageSize)
+	} else {
+		cfg.PageSize = file.PageSize
+	}
+	applyDefaultView(cfg, file)
+
+	if err := applyKeyConfig(&cfg.Keys, &cfg.TableKeys, file.Keys); err != nil {
+		return fmt.Errorf("invalid key bindings in %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// applyDefaultView sets the filters and the sort the table starts with,
+// invalid values are reported and ignored.
+func applyDefaultView(cfg *config, file fileConfig) {
+	if name := strings.ToLower(file.DefaultFilter.Status); name != "" && name != "all" {
+		if status, ok := entity.TaskStatusFromString[name]; ok {
+			cfg.Filters.Status = status
+		} else {
+			log.Printf("invalid default status filter %q, showing all statuses", name)
+		}
+	}
+
+	if name := strings.ToLower(file.DefaultFilter.Priority); name != "" && name != "all" {
+		if priority, ok := entity.TaskPriorityFromString[name]; ok {
+			cfg.Filters.Priority = priority
+		} else {
+			log.Printf("invalid default priority filter %q, showing all priorities", name)
+		}
+	}
+
+	if column := file.DefaultSort.Column; column != "" {
+		if validSortColumn(column) {
+			cfg.SortColumn = column
+			cfg.SortDesc = file.DefaultSort.Descending
+		} else {
+			log.Printf("invalid default sort column %q, ignoring it", column)
+		}
+	}
+
+	if column := file.SortTiebreaker.Column; column != "" {
+		if validSortColumn(column) {
+			cfg.TiebreakColumn = column
+			cfg.TiebreakDesc = file.SortTiebreaker.Descending
+		} else {
+			log.Printf("invalid sort tiebreaker column %q, ignoring it", column)
+		}
+	}
+}
+
+// applyViewFlags sets the filters and the sort given on the command line. The
+// filter is a list like "status=todo,priority=high", the sort a column with an
+// optional ":desc" suffix.
+func applyViewFlags(cfg *config, filter string, sort string) error {
+	if filter != "" {
+		for _, part := range strings.Split(filter, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			value = strings.ToLower(value)
+
+			switch name {
+			case "status":
+				status, ok := entity.TaskStatusFromString[value]
+				if !ok && value != "all" {
+					return fmt.Errorf("invalid status filter %q", value)
+				}
+				cfg.Filters.Status = status
+
+			case "priority":
+				priority, ok := entity.TaskPriorityFromString[value]
+				if !ok && value != "all" {
+					return fmt.Errorf("invalid priority filter %q", value)
+				}
+				cfg.Filters.Priority = priority
+
+			default:
+				return fmt.Errorf("invalid filter %q, expected status=… or priority=…", part)
+			}
+		}
+	}
+
+	if sort != "" {
+		column, direction, _ := strings.Cut(sort, ":")
+		if !validSortColumn(column) {
+			return fmt.Errorf("invalid sort column %q", column)
+		}
+		if direction != "" && direction != "asc" && direction != "desc" {
+			return fmt.Errorf("invalid sort direction %q, expected asc or desc", direction)
+		}
+
+		cfg.SortColumn = column
+		cfg.SortDesc = direction == "desc"
+	}
+
+	return nil
+}
+
+// validateTimeFormat checks that layout contains at least one time element and
+// that times formatted with it can be parsed back.
+func validateTimeFormat(layout string) error {
+	reference := time.Date(2017, time.November, 23, 19, 37, 48, 0, time.UTC)
+
+	formatted := reference.Format(layout)
+	if formatted == layout {
+		return fmt.Errorf("time format %q contains no time elements", layout)
+	}
+
+	if _, err := time.Parse(layout, formatted); err != nil {
+		return fmt.Errorf("invalid time format %q: %w", layout, err)
+	}
+
+	return nil
+}
+
+// writeConfigValue sets a single top level value in the config file, keeping
+// the other values as they are.
+func writeConfigValue(path string, name string, value interface{}) error {
+	values := map[string]json.RawMessage{}
+
+	buf, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(buf, &values); err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	values[name] = raw
+
+	buf, err = json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(buf, '\n'), 0644)
+}
+
+// parseThreshold parses a due date threshold like "72h", an invalid one is
+// reported and fallback is used.
+func parseThreshold(name string, value string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Printf("invalid %s threshold %q, using %s", name, value, fallback)
+		return fallback
+	}
+
+	return d
+}
+
+// loadConfig reads the configuration from the config file and the
+// environment. Invalid values are reported and replaced by their defaults, only
+// an unreadable config file or invalid key bindings are returned as errors.
+func loadConfig() (config, error) {
+	cfg := defaultConfig()
+
+	if path, err := stateFilePath(); err != nil {
+		log.Printf("can't locate the state file: %s", err)
+	} else {
+		cfg.StatePath = path
+	}
+
+	if path, err := configFilePath(); err != nil {
+		log.Printf("can't locate the config file: %s", err)
+	} else if err := readConfigFile(path, &cfg); err != nil {
+		return cfg, err
+	} else {
+		cfg.ConfigPath = path
+	}
+
+	if format := os.Getenv("TSKUI_TIME_FORMAT"); format != "" {
+		if err := validateTimeFormat(format); err != nil {
+			log.Printf("%s, using %q", err, defaultTimeFormat)
+		} else {
+			cfg.TimeFormat = format
+		}
+	}
+
+	if interval := os.Getenv("TSKUI_REFRESH_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d < 0 {
+			log.Printf("invalid refresh interval %q, using %s", interval, defaultRefreshInterval)
+		} else {
+			cfg.RefreshInterval = d
+		}
+	}
+
+	// the environment wins over the config file
+	if soon := os.Getenv("TSKUI_DUE_SOON"); soon != "" {
+		cfg.DueSoon = parseThreshold("due soon", soon, cfg.DueSoon)
+	}
+
+	if relative := os.Getenv("TSKUI_RELATIVE_DUE"); relative != "" {
+		cfg.RelativeDue = parseThreshold("relative due", relative, cfg.RelativeDue)
+	}
+
+	if readOnly := os.Getenv("TSKUI_READONLY"); readOnly != "" {
+		b, err := strconv.ParseBool(readOnly)
+		if err != nil {
+			log.Printf("invalid read-only value %q, ignoring it", readOnly)
+		} else {
+			cfg.ReadOnly = b
+		}
+	}
+
+	return cfg, nil
+}
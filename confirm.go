@@ -0,0 +1,42 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// confirmDoneMsg is sent when the confirmation dialog is closed.
+type confirmDoneMsg struct{}
+
+// confirmDialog asks a yes/no question and runs action when answered with yes.
+// Every key is captured while the dialog is open.
+type confirmDialog struct {
+	question string
+	action   tea.Cmd
+}
+
+func newConfirmDialog(question string, action tea.Cmd) confirmDialog {
+	return confirmDialog{
+		question: question,
+		action:   action,
+	}
+}
+
+func (c confirmDialog) Update(msg tea.Msg) (confirmDialog, tea.Cmd) {
+	done := func() tea.Msg { return confirmDoneMsg{} }
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "y", "Y":
+			return c, tea.Batch(done, c.action)
+
+		case "n", "N", "esc":
+			return c, done
+		}
+	}
+
+	return c, nil
+}
+
+func (c confirmDialog) View() string {
+	return confirmStyle.Render(c.question + " [y/n]")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// createdDateFormat is the format of the dates of a custom creation range.
+const createdDateFormat = "2006-01-02"
+
+// createdRange limits the listed tasks to the ones created from (inclusive)
+// to (exclusive), a zero bound leaves that side open. value is what was typed
+// to set it, label what the footer shows.
+type createdRange struct {
+	from  time.Time
+	to    time.Time
+	value string
+	label string
+}
+
+// createdRangeMsg is sent when the creation range filter is changed.
+type createdRangeMsg struct {
+	r createdRange
+}
+
+func (r createdRange) active() bool {
+	return r.value != ""
+}
+
+func (r createdRange) contains(t time.Time) bool {
+	if !r.from.IsZero() && t.Before(r.from) {
+		return false
+	}
+
+	return r.to.IsZero() || t.Before(r.to)
+}
+
+// parseCreatedRange parses a creation range: "today", "week", "month", a
+// number of days like "30d", or dates like "2022-10-01..2022-10-14" where
+// either date may be left out. An empty value clears the filter.
+func parseCreatedRange(value string, now time.Time) (createdRange, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	r := createdRange{value: value, label: value}
+
+	switch value {
+	case "":
+		return createdRange{}, nil
+
+	case "today":
+		r.from, r.to = midnight, midnight.AddDate(0, 0, 1)
+		return r, nil
+
+	case "week":
+		// the weeks start on monday
+		r.from = midnight.AddDate(0, 0, -(int(now.Weekday())+6)%7)
+		r.to = r.from.AddDate(0, 0, 7)
+		r.label = "this week"
+		return r, nil
+
+	case "month":
+		r.from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		r.to = r.from.AddDate(0, 1, 0)
+		r.label = "this month"
+		return r, nil
+	}
+
+	if strings.HasSuffix(value, "d") {
+		days := strings.TrimSuffix(value, "d")
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return createdRange{}, fmt.Errorf("invalid number of days %q", days)
+		}
+
+		r.from = now.AddDate(0, 0, -n)
+		r.label = fmt.Sprintf("last %d days", n)
+		return r, nil
+	}
+
+	from, to, ok := strings.Cut(value, "..")
+	if !ok {
+		return createdRange{}, fmt.Errorf("invalid range %q, expected today, week, month, 30d or %s..%s", value, createdDateFormat, createdDateFormat)
+	}
+	if from == "" && to == "" {
+		return createdRange{}, fmt.Errorf("the range needs a start or an end date")
+	}
+
+	if from != "" {
+		date, err := time.ParseInLocation(createdDateFormat, from, now.Location())
+		if err != nil {
+			return createdRange{}, fmt.Errorf("invalid start date %q, expected %s", from, createdDateFormat)
+		}
+		r.from = date
+	}
+
+	if to != "" {
+		date, err := time.ParseInLocation(createdDateFormat, to, now.Location())
+		if err != nil {
+			return createdRange{}, fmt.Errorf("invalid end date %q, expected %s", to, createdDateFormat)
+		}
+		// the end date is included
+		r.to = date.AddDate(0, 0, 1)
+	}
+
+	if !r.from.IsZero() && !r.to.IsZero() && !r.from.Before(r.to) {
+		return createdRange{}, fmt.Errorf("the range ends before it starts")
+	}
+
+	return r, nil
+}
+
+// filterCreated is the action of the creation range prompt.
+func filterCreated(value string) (tea.Cmd, error) {
+	r, err := parseCreatedRange(value, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return func() tea.Msg { return createdRangeMsg{r: r} }, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/driver"
+	"github.com/kakengloh/tsk/repository"
+)
+
+// dbOpenedMsg is sent after another task database has been opened.
+type dbOpenedMsg struct {
+	name    string
+	path    string
+	tr      repository.TaskRepository
+	closeDB func()
+}
+
+// defaultProfile names the default tsk database, unless the config file has
+// a profile of that name.
+const defaultProfile = "default"
+
+// resolveDB returns the path of the database named by value, either a profile
+// of the config file or a path.
+func resolveDB(profiles map[string]string, value string) (string, error) {
+	if path, ok := profiles[value]; ok {
+		return path, nil
+	}
+
+	if value == defaultProfile {
+		dir, err := driver.GetDataDir()
+		if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(dir, "bolt.db"), nil
+	}
+
+	return value, nil
+}
+
+func samePath(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+
+	return errA == nil && errB == nil && a == b
+}
+
+// switchDB opens the database of a profile or an existing database file. The
+// current database is closed once the other one is open.
+func switchDB(profiles map[string]string, current string) func(string) (tea.Cmd, error) {
+	return func(value string) (tea.Cmd, error) {
+		name := strings.TrimSpace(value)
+		if name == "" {
+			return nil, fmt.Errorf("profile or path must not be empty")
+		}
+
+		path, err := resolveDB(profiles, name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := profiles[name]; !ok && name != defaultProfile {
+			if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("no profile or database %q", name)
+			}
+		}
+		if samePath(path, current) {
+			return nil, fmt.Errorf("%s is already open", name)
+		}
+
+		return retryable(func() tea.Msg {
+			db, closeDB, err := openDB(path)
+			if err != nil {
+				return errMsg{err: fmt.Errorf("failed to open %s: %w", path, err)}
+			}
+
+			tr, err := repository.NewBoltTaskRepository(db)
+			if err != nil {
+				closeDB()
+				return errMsg{err: fmt.Errorf("failed to open %s: %w", path, err)}
+			}
+
+			return dbOpenedMsg{name: name, path: db.Path(), tr: logRepository(tr), closeDB: closeDB}
+		}), nil
+	}
+}
+
+// dbOpened closes the current database and lists the tasks of the opened one.
+// The undo history and the selection belong to the closed database and are
+// dropped.
+func dbOpened(m Model, msg dbOpenedMsg) (Model, tea.Cmd) {
+	if m.server != nil {
+		m.server.setRepository(msg.tr)
+	}
+	if m.closeDB != nil {
+		m.closeDB()
+	}
+
+	m.pins = allPins(m)
+	m.pinned = idSet(m.pins[msg.path])
+	m.archive = allArchived(m)
+	m.archived = idSet(m.archive[msg.path])
+	m.taskRepository = trackRepository(msg.tr, m.ops)
+	m.closeDB = msg.closeDB
+	m.dbName = msg.name
+	if _, ok := m.profiles[msg.name]; !ok && msg.name == defaultProfile {
+		m.dbName = ""
+	}
+	m.dbPath = msg.path
+	m.dbModTime = dbModTime(msg.path)
+	m.undo = nil
+	m.selected = nil
+	m.mode = modeTable
+
+	return loadTasks(setStatus(m, "opened "+msg.name), true)
+}
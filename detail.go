@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kakengloh/tsk/entity"
+)
+
+var (
+	detailHelpStyle = lipgloss.NewStyle().Faint(true)
+)
+
+// doingFor tells how long the task has been in progress. tsk doesn't record
+// when the status changed, so it is counted from the creation of the task.
+func doingFor(task entity.Task) string {
+	return elapsedFormat.Format(task.CreatedAt) + " (since created)"
+}
+
+// detailHintLines is the number of lines below the scrolled task details.
+const detailHintLines = 2
+
+// renderTaskDetail renders every field of the task without truncation,
+// wrapping long lines to width. match tells where the search matched the task,
+// if it is searched for.
+func renderTaskDetail(task entity.Task, match string, width int, format string) string {
+	detail := renderTaskFields(task, width, format)
+	if match != "" {
+		detail += "\n" + detailLabelStyle.Render("Matched") + detailValueStyle.Render(match) + "\n"
+	}
+
+	return detail
+}
+
+// setDetailContent renders the details of the task into the viewport, which
+// scrolls them when they don't fit the terminal. Short details only take the
+// lines they need.
+func setDetailContent(m Model) Model {
+	match := ""
+	if m.search != "" {
+		match = searchMatch(m.detail, m.search)
+	}
+
+	content := renderTaskDetail(m.detail, match, m.width, m.timeFormat)
+
+	height := lipgloss.Height(content)
+	if available := m.height - detailHintLines; m.height > 0 && height > available {
+		height = available
+		if height < 1 {
+			height = 1
+		}
+	}
+
+	m.detailView.Width = m.width
+	m.detailView.Height = height
+	m.detailView.SetContent(content)
+
+	return m
+}
+
+// renderDetailView renders the scrolled details and the keys to use.
+func renderDetailView(m Model) string {
+	hint := m.keys.Back.Help().Key + ": back"
+	if !m.detailView.AtTop() || !m.detailView.AtBottom() {
+		hint += fmt.Sprintf(" · j/k: scroll (%.0f%%)", m.detailView.ScrollPercent()*100)
+	}
+
+	return m.detailView.View() + "\n" + detailHelpStyle.Render(hint) + "\n"
+}
+
+// renderTaskFields renders the title, the fields and the notes of the task.
+func renderTaskFields(task entity.Task, width int, format string) string {
+	wrap := lipgloss.NewStyle()
+	if width > 0 {
+		wrap = wrap.Width(width)
+	}
+
+	b := strings.Builder{}
+
+	b.WriteString(wrap.Render(detailTitleStyle.Render(task.Title)))
+	b.WriteString("\n\n")
+
+	due := ""
+	if !task.Due.IsZero() {
+		due = task.Due.Format(format) + " (" + relativeFormat.Format(task.Due) + ")"
+	}
+
+	type taskField struct {
+		label string
+		value string
+	}
+
+	fields := []taskField{
+		{"Status", entity.TaskStatusToString[task.Status]},
+		{"Priority", entity.TaskPriorityToString[task.Priority]},
+		{"Created", task.CreatedAt.Format(format)},
+		{"Due Date", due},
+	}
+
+	if task.Status == entity.TaskStatusDoing {
+		fields = append(fields, taskField{"Doing for", doingFor(task)})
+	}
+
+	for _, field := range fields {
+		b.WriteString(detailLabelStyle.Render(field.label))
+		b.WriteString(detailValueStyle.Render(field.value))
+		b.WriteString("\n")
+	}
+
+	if len(task.Notes) > 0 {
+		b.WriteString("\n")
+		b.WriteString(detailLabelStyle.Render("Notes"))
+		b.WriteString("\n")
+
+		noteStyle := detailNoteStyle.Copy()
+		if width > 2 {
+			noteStyle = noteStyle.Width(width - 2)
+		}
+
+		for _, note := range task.Notes {
+			b.WriteString("• ")
+			b.WriteString(strings.ReplaceAll(noteStyle.Render(note), "\n", "\n  "))
+			b.WriteString("\n")
+		}
+	}
+
+	return b.String()
+}
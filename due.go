@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// dueLayouts are the absolute due dates, the ones without a time are due
+	// at the start of the day.
+	dueLayouts = []string{
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	}
+
+	// dueClockLayouts are the times of day that may follow a day, like
+	// "tomorrow 5pm".
+	dueClockLayouts = []string{
+		"15:04",
+		"3pm",
+		"3:04pm",
+	}
+
+	dueWeekdays = map[string]time.Weekday{
+		"monday": time.Monday, "mon": time.Monday,
+		"tuesday": time.Tuesday, "tue": time.Tuesday,
+		"wednesday": time.Wednesday, "wed": time.Wednesday,
+		"thursday": time.Thursday, "thu": time.Thursday,
+		"friday": time.Friday, "fri": time.Friday,
+		"saturday": time.Saturday, "sat": time.Saturday,
+		"sunday": time.Sunday, "sun": time.Sunday,
+	}
+
+	dueUnits = map[string]time.Duration{
+		"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+		"h": time.Hour, "hour": time.Hour, "hours": time.Hour,
+		"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	}
+)
+
+// parseDue parses a due date: a date like "2006-01-02 15:04" or "2006-01-02",
+// a day like "today", "tomorrow", "friday" or "next monday" with an optional
+// time like "5pm", "5:30pm" or "17:00", a time alone for today, or an offset
+// like "in 3 days", "in 2h" or "in a week". An empty value is no due date.
+func parseDue(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range dueLayouts {
+		if due, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return due, nil
+		}
+	}
+
+	words := strings.Fields(strings.ToLower(value))
+
+	due, ok := time.Time{}, false
+	if words[0] == "in" {
+		due, words, ok = dueOffset(words[1:], now)
+	} else {
+		due, words, ok = dueDay(words, now)
+	}
+
+	if len(words) > 0 && words[0] == "at" {
+		words = words[1:]
+	}
+	if len(words) > 0 {
+		clock, clockOK := dueClock(strings.Join(words, ""))
+		if !clockOK {
+			return time.Time{}, invalidDue(value)
+		}
+		if !ok {
+			due = now
+		}
+		due = time.Date(due.Year(), due.Month(), due.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+		ok = true
+	}
+
+	if !ok {
+		return time.Time{}, invalidDue(value)
+	}
+
+	return due, nil
+}
+
+func invalidDue(value string) error {
+	return fmt.Errorf("invalid due date %q, try 2006-01-02 15:04, tomorrow 5pm, next monday or in 3 days", value)
+}
+
+// dueDay parses the day the words start with, at the start of the day, and
+// returns the words after it.
+func dueDay(words []string, now time.Time) (time.Time, []string, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch words[0] {
+	case "today", "tonight":
+		return today, words[1:], true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), words[1:], true
+	}
+
+	day := words
+	if day[0] == "next" && len(day) > 1 {
+		day = day[1:]
+	}
+	if weekday, ok := dueWeekdays[day[0]]; ok {
+		// the next one after today, "friday" on a friday is a week later
+		days := (int(weekday)-int(now.Weekday())+6)%7 + 1
+		return today.AddDate(0, 0, days), day[1:], true
+	}
+
+	if date, err := time.ParseInLocation(createdDateFormat, words[0], now.Location()); err == nil {
+		return date, words[1:], true
+	}
+
+	return time.Time{}, words, false
+}
+
+// dueOffset parses an offset from now like "3 days", "3d" or "a week", and
+// returns the words after it. Offsets of days and weeks keep the time of day,
+// unless a time follows.
+func dueOffset(words []string, now time.Time) (time.Time, []string, bool) {
+	if len(words) == 0 {
+		return time.Time{}, words, false
+	}
+
+	number, unit, rest := words[0], "", words[1:]
+	if i := strings.IndexFunc(number, func(r rune) bool { return r < '0' || r > '9' }); i > 0 {
+		number, unit = number[:i], number[i:]
+	} else if len(rest) > 0 {
+		unit, rest = rest[0], rest[1:]
+	}
+
+	n, err := strconv.Atoi(number)
+	if number == "a" || number == "an" {
+		n, err = 1, nil
+	}
+	step, ok := dueUnits[unit]
+	if err != nil || n <= 0 || !ok {
+		return time.Time{}, words, false
+	}
+
+	if step%(24*time.Hour) == 0 {
+		return now.AddDate(0, 0, n*int(step/(24*time.Hour))), rest, true
+	}
+
+	return now.Add(time.Duration(n) * step), rest, true
+}
+
+// dueClock parses a time of day like "5pm", "5:30pm" or "17:00".
+func dueClock(value string) (time.Time, bool) {
+	for _, layout := range dueClockLayouts {
+		if clock, err := time.Parse(layout, value); err == nil {
+			return clock, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/entity"
+)
+
+const (
+	defaultCSVExportPath  = "tsk-export.csv"
+	defaultJSONExportPath = "tsk-export.json"
+)
+
+// jsonTask is the JSON representation of an exported task, status and
+// priority are included both as their raw value and as a readable name.
+type jsonTask struct {
+	ID           int                 `json:"id"`
+	Title        string              `json:"title"`
+	Status       entity.TaskStatus   `json:"status"`
+	StatusName   string              `json:"status_name"`
+	Priority     entity.TaskPriority `json:"priority"`
+	PriorityName string              `json:"priority_name"`
+	Due          *time.Time          `json:"due,omitempty"`
+	Notes        []string            `json:"notes"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+}
+
+// tasksExportedMsg is sent after the tasks have been written to a file.
+type tasksExportedMsg struct {
+	path  string
+	count int
+}
+
+func writeCSV(path string, tasks []entity.Task, format string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+
+	if err := w.Write([]string{"id", "title", "status", "priority", "created", "due", "notes"}); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		due := ""
+		if !task.Due.IsZero() {
+			due = task.Due.Format(format)
+		}
+
+		record := []string{
+			strconv.Itoa(task.ID),
+			task.Title,
+			entity.TaskStatusToString[task.Status],
+			entity.TaskPriorityToString[task.Priority],
+			task.CreatedAt.Format(format),
+			due,
+			strings.Join(task.Notes, "\n"),
+		}
+
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// exportedTasks converts the tasks to their JSON representation.
+func exportedTasks(tasks []entity.Task) []jsonTask {
+	exported := make([]jsonTask, len(tasks))
+
+	for i, task := range tasks {
+		exported[i] = jsonTask{
+			ID:           task.ID,
+			Title:        task.Title,
+			Status:       task.Status,
+			StatusName:   entity.TaskStatusToString[task.Status],
+			Priority:     task.Priority,
+			PriorityName: entity.TaskPriorityToString[task.Priority],
+			Notes:        task.Notes,
+			CreatedAt:    task.CreatedAt,
+			UpdatedAt:    task.UpdatedAt,
+		}
+
+		if !task.Due.IsZero() {
+			due := task.Due
+			exported[i].Due = &due
+		}
+
+		if exported[i].Notes == nil {
+			exported[i].Notes = []string{}
+		}
+	}
+
+	return exported
+}
+
+func writeJSON(path string, tasks []entity.Task) error {
+	buf, err := json.MarshalIndent(exportedTasks(tasks), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(buf, '\n'), 0644)
+}
+
+func exportTasks(tasks []entity.Task, write func(path string, tasks []entity.Task) error) func(string) (tea.Cmd, error) {
+	return func(value string) (tea.Cmd, error) {
+		path := strings.TrimSpace(value)
+		if path == "" {
+			return nil, fmt.Errorf("path must not be empty")
+		}
+
+		return retryable(func() tea.Msg {
+			if err := write(path, tasks); err != nil {
+				return errMsg{err: fmt.Errorf("failed to export tasks: %w", err)}
+			}
+
+			return tasksExportedMsg{path: path, count: len(tasks)}
+		}), nil
+	}
+}
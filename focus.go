@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kakengloh/tsk/entity"
+)
+
+// minFocusWidth is the narrowest the focused task is rendered, it takes two
+// thirds of wider terminals.
+const minFocusWidth = 40
+
+// focusTasks returns the loaded tasks in progress, the ones focus mode shows.
+func focusTasks(m Model) []entity.Task {
+	tasks := []entity.Task{}
+	for _, task := range m.all {
+		if task.Status == entity.TaskStatusDoing {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks
+}
+
+// focusedTask returns the task shown in focus mode, the only task in progress
+// or the one picked from several.
+func focusedTask(m Model) (entity.Task, bool) {
+	tasks := focusTasks(m)
+	if len(tasks) == 1 {
+		return tasks[0], true
+	}
+
+	for _, task := range tasks {
+		if task.ID == m.focusID {
+			return task, true
+		}
+	}
+
+	return entity.Task{}, false
+}
+
+// enterFocus switches to focus mode. The status and priority filters are
+// cleared so every task in progress is loaded, they are restored when focus
+// mode is left.
+func enterFocus(m Model) (Model, tea.Cmd) {
+	m.mode = modeFocus
+	m.focusID = 0
+	m.focusCursor = 0
+	m.focusFilters = m.filters
+
+	if m.filters == (entity.TaskFilters{}) {
+		return m, nil
+	}
+
+	m.filters = entity.TaskFilters{}
+
+	return loadTasks(m, false)
+}
+
+func leaveFocus(m Model) (Model, tea.Cmd) {
+	m.mode = modeTable
+
+	if m.filters == m.focusFilters {
+		return m, nil
+	}
+
+	m.filters = m.focusFilters
+
+	return loadTasks(m, false)
+}
+
+// updateFocus handles the keys of focus mode, picking a task when several are
+// in progress.
+func updateFocus(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back), key.Matches(msg, m.keys.Focus):
+		return leaveFocus(m)
+	case key.Matches(msg, m.keys.Quit):
+		return quit(m)
+	}
+
+	if _, ok := focusedTask(m); ok {
+		return m, nil
+	}
+
+	tasks := focusTasks(m)
+	tableKeys := m.tableModel.KeyMap()
+
+	switch {
+	case key.Matches(msg, tableKeys.RowDown):
+		if m.focusCursor < len(tasks)-1 {
+			m.focusCursor++
+		}
+	case key.Matches(msg, tableKeys.RowUp):
+		if m.focusCursor > 0 {
+			m.focusCursor--
+		}
+	case key.Matches(msg, m.keys.Detail):
+		if m.focusCursor < len(tasks) {
+			m.focusID = tasks[m.focusCursor].ID
+		}
+	}
+
+	return m, nil
+}
+
+// renderFocus renders the task in progress centered in the terminal, or the
+// list to pick it from.
+func renderFocus(m Model) string {
+	width := m.width * 2 / 3
+	if width < minFocusWidth {
+		width = minFocusWidth
+	}
+	width -= focusStyle.GetHorizontalFrameSize()
+
+	back := m.keys.Back.Help().Key + ": back"
+	tasks := focusTasks(m)
+
+	var content, hint string
+	if task, ok := focusedTask(m); ok {
+		content = renderTaskFields(task, width, m.timeFormat)
+		hint = back
+	} else if m.loading {
+		content = m.spinner.View() + " loading tasks"
+		hint = back
+	} else if len(tasks) == 0 {
+		content = "No task is in progress."
+		hint = back
+	} else {
+		b := strings.Builder{}
+		b.WriteString(promptLabelStyle.Render("Pick the task to focus on"))
+		b.WriteString("\n\n")
+
+		for i, task := range tasks {
+			line := fmt.Sprintf("  %d  %s", task.ID, task.Title)
+			if i == m.focusCursor {
+				line = helpKeyStyle.Render(fmt.Sprintf("> %d  %s", task.ID, task.Title))
+			}
+
+			b.WriteString(lipgloss.NewStyle().MaxWidth(width).Render(line))
+			b.WriteString("\n")
+		}
+
+		content = strings.TrimSuffix(b.String(), "\n")
+		hint = m.keys.Detail.Help().Key + ": focus · " + back
+	}
+
+	view := focusStyle.Copy().Width(width+focusStyle.GetHorizontalPadding()).Render(strings.TrimSuffix(content, "\n")) +
+		"\n" + detailHelpStyle.Render(hint)
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, view)
+}
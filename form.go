@@ -0,0 +1,166 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kakengloh/tsk/entity"
+)
+
+const (
+	formFieldTitle = iota
+	formFieldPriority
+	formFieldDue
+	formFieldNotes
+	formFieldCount
+)
+
+var (
+	formLabels = [formFieldCount]string{
+		formFieldTitle:    "Title",
+		formFieldPriority: "Priority",
+		formFieldDue:      "Due Date",
+		formFieldNotes:    "Notes",
+	}
+
+	formHelpStyle = lipgloss.NewStyle().Faint(true)
+)
+
+// taskFormSubmitMsg is sent by the form when the user submits a valid task.
+type taskFormSubmitMsg struct {
+	title    string
+	priority entity.TaskPriority
+	due      time.Time
+	note     string
+}
+
+// taskFormCancelMsg is sent by the form when the user cancels it.
+type taskFormCancelMsg struct{}
+
+type taskForm struct {
+	inputs  [formFieldCount]textinput.Model
+	focused int
+	err     string
+}
+
+func newTaskForm() taskForm {
+	f := taskForm{}
+
+	for i := range f.inputs {
+		input := textinput.New()
+		input.Prompt = ""
+		input.Width = 40
+		f.inputs[i] = input
+	}
+
+	f.inputs[formFieldPriority].Placeholder = "low, medium or high"
+	f.inputs[formFieldDue].Placeholder = "YYYY-MM-DD HH:MM, tomorrow 5pm, in 3d"
+	f.inputs[formFieldTitle].Focus()
+
+	return f
+}
+
+func (f taskForm) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// dirty reports whether anything has been typed into the form.
+func (f taskForm) dirty() bool {
+	for _, input := range f.inputs {
+		if input.Value() != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f taskForm) focus(field int) taskForm {
+	f.inputs[f.focused].Blur()
+	f.focused = (field + formFieldCount) % formFieldCount
+	f.inputs[f.focused].Focus()
+
+	return f
+}
+
+func (f taskForm) submit() (taskForm, tea.Cmd) {
+	title := strings.TrimSpace(f.inputs[formFieldTitle].Value())
+	if title == "" {
+		f.err = "title must not be empty"
+		return f.focus(formFieldTitle), nil
+	}
+
+	priority, ok := entity.TaskPriorityFromString[strings.ToLower(strings.TrimSpace(f.inputs[formFieldPriority].Value()))]
+	if !ok {
+		f.err = "priority must be low, medium or high"
+		return f.focus(formFieldPriority), nil
+	}
+
+	due, err := parseDue(f.inputs[formFieldDue].Value(), time.Now())
+	if err != nil {
+		f.err = err.Error()
+		return f.focus(formFieldDue), nil
+	}
+
+	msg := taskFormSubmitMsg{
+		title:    title,
+		priority: priority,
+		due:      due,
+		note:     strings.TrimSpace(f.inputs[formFieldNotes].Value()),
+	}
+
+	return f, func() tea.Msg { return msg }
+}
+
+func (f taskForm) Update(msg tea.Msg) (taskForm, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			return f, func() tea.Msg { return taskFormCancelMsg{} }
+
+		case "tab", "down":
+			return f.focus(f.focused + 1), nil
+
+		case "shift+tab", "up":
+			return f.focus(f.focused - 1), nil
+
+		case "enter":
+			if f.focused < formFieldCount-1 {
+				return f.focus(f.focused + 1), nil
+			}
+			return f.submit()
+		}
+	}
+
+	var cmd tea.Cmd
+	f.inputs[f.focused], cmd = f.inputs[f.focused].Update(msg)
+
+	return f, cmd
+}
+
+func (f taskForm) View() string {
+	b := strings.Builder{}
+
+	b.WriteString(formTitleStyle.Render("New task"))
+	b.WriteString("\n\n")
+
+	for i, input := range f.inputs {
+		b.WriteString(formLabelStyle.Render(formLabels[i]))
+		b.WriteString(input.View())
+		b.WriteString("\n")
+	}
+
+	if f.err != "" {
+		b.WriteString("\n")
+		b.WriteString(formErrorStyle.Render(f.err))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(formHelpStyle.Render("tab/enter: next field • enter on last field: create • esc: cancel"))
+
+	return b.String()
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/evertras/bubble-table/table"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/util"
+)
+
+// groupKey is the hidden row data key ordering the rows of the grouped view,
+// the header of a group sorts right before its tasks.
+var groupKey = sortKey("group")
+
+// groupHeaderKey marks the header rows of the grouped view.
+const groupHeaderKey = "group_header"
+
+// grouping is what the rows of the grouped view are grouped by.
+type grouping int
+
+const (
+	groupNone grouping = iota
+	groupStatus
+	groupDue
+)
+
+// groupingNames are the names of the groupings in the state file.
+var groupingNames = map[grouping]string{
+	groupStatus: "status",
+	groupDue:    "due",
+}
+
+// groupStatuses lists the statuses the grouped view shows, in order.
+var groupStatuses = []entity.TaskStatus{
+	entity.TaskStatusTodo,
+	entity.TaskStatusDoing,
+	entity.TaskStatusDone,
+}
+
+// dueBucket is a group of the view grouped by due date.
+type dueBucket int
+
+const (
+	bucketOverdue dueBucket = iota + 1
+	bucketToday
+	bucketThisWeek
+	bucketNextWeek
+	bucketLater
+	bucketNoDue
+	// bucketDoneBefore holds the done tasks that were due before today, they
+	// are not overdue
+	bucketDoneBefore
+)
+
+var dueBucketTitles = map[dueBucket]string{
+	bucketOverdue:    "Overdue",
+	bucketToday:      "Today",
+	bucketThisWeek:   "This week",
+	bucketNextWeek:   "Next week",
+	bucketLater:      "Later",
+	bucketNoDue:      "No due date",
+	bucketDoneBefore: "Done, due before today",
+}
+
+// dueBucketOf returns the due date group of the task. The weeks start on
+// monday, like the ones of the creation date filter.
+func dueBucketOf(task entity.Task, now time.Time) dueBucket {
+	if task.Due.IsZero() {
+		return bucketNoDue
+	}
+	if isOverdue(task) {
+		return bucketOverdue
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	nextWeek := today.AddDate(0, 0, 7-(int(now.Weekday())+6)%7)
+	due := task.Due.In(now.Location())
+
+	switch {
+	case due.Before(today):
+		return bucketDoneBefore
+	case due.Before(today.AddDate(0, 0, 1)):
+		return bucketToday
+	case due.Before(nextWeek):
+		return bucketThisWeek
+	case due.Before(nextWeek.AddDate(0, 0, 7)):
+		return bucketNextWeek
+	}
+
+	return bucketLater
+}
+
+// groupRank orders the task in the grouped view, right after the header of
+// its group.
+func groupRank(g grouping, task entity.Task, now time.Time) int {
+	if g == groupDue {
+		return groupOrder(int(dueBucketOf(task, now)))
+	}
+
+	return groupOrder(int(task.Status))
+}
+
+// groupOrder returns the rank of the tasks of the nth group, the header of the
+// group has the one before.
+func groupOrder(n int) int {
+	return 2*n + 1
+}
+
+// groupHeaderRow returns the row shown above the count tasks of a group.
+func groupHeaderRow(title string, rank int, count int) table.Row {
+	return table.NewRow(table.RowData{
+		columnKeyTitle: fmt.Sprintf("%s (%d)", title, count),
+		groupKey:       rank - 1,
+		groupHeaderKey: true,
+	}).WithStyle(groupHeaderStyle)
+}
+
+// groupHeaderRows returns the header rows of the groups of tasks, the empty
+// groups have none.
+func groupHeaderRows(g grouping, tasks []entity.Task, now time.Time) []table.Row {
+	counts := map[int]int{}
+	for _, task := range tasks {
+		counts[groupRank(g, task, now)]++
+	}
+
+	rows := []table.Row{}
+
+	if g == groupDue {
+		for bucket := bucketOverdue; bucket <= bucketDoneBefore; bucket++ {
+			if rank := groupOrder(int(bucket)); counts[rank] > 0 {
+				rows = append(rows, groupHeaderRow(dueBucketTitles[bucket], rank, counts[rank]))
+			}
+		}
+
+		return rows
+	}
+
+	for _, status := range groupStatuses {
+		if rank := groupOrder(int(status)); counts[rank] > 0 {
+			title := util.CapitalizeString(entity.TaskStatusToString[status])
+			rows = append(rows, groupHeaderRow(title, rank, counts[rank]))
+		}
+	}
+
+	return rows
+}
+
+// toggleGrouping groups the rows by g, or stops grouping them if they already
+// are.
+func toggleGrouping(m Model, g grouping) Model {
+	if m.grouping == g {
+		m.grouping = groupNone
+	} else {
+		m.grouping = g
+	}
+
+	id, ok := highlightedTaskID(m)
+	m = applySort(updateRows(m))
+	if ok {
+		m = highlightTask(m, id)
+	}
+
+	return m
+}
+
+func isGroupHeader(row table.Row) bool {
+	_, ok := row.Data[groupHeaderKey]
+	return ok
+}
+
+// skipGroupHeader moves the cursor off a header row, up if the cursor was
+// moving up and down otherwise.
+func skipGroupHeader(m Model, up bool) Model {
+	rows := m.tableModel.GetVisibleRows()
+	i := m.tableModel.GetHighlightedRowIndex()
+
+	if i >= len(rows) || !isGroupHeader(rows[i]) {
+		return m
+	}
+
+	if (up && i > 0) || i+1 >= len(rows) {
+		i--
+	} else {
+		i++
+	}
+
+	if i >= 0 {
+		m.tableModel = m.tableModel.WithHighlightedRow(i)
+	}
+
+	return m
+}
+
+// movingUp reports whether msg moves the table cursor up.
+func movingUp(m Model, msg tea.Msg) bool {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		keys := m.tableModel.KeyMap()
+		return key.Matches(msg, keys.RowUp, keys.PageUp)
+
+	case tea.MouseMsg:
+		return msg.Type == tea.MouseWheelUp
+	}
+
+	return false
+}
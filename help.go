@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	helpHintStyle = lipgloss.NewStyle().Faint(true)
+)
+
+// helpHintLines is the number of lines below the scrolled help.
+const helpHintLines = 2
+
+// renderHelp lists the enabled bindings.
+func renderHelp(bindings []key.Binding) string {
+	width := 0
+	for _, binding := range bindings {
+		if w := lipgloss.Width(binding.Help().Key); w > width {
+			width = w
+		}
+	}
+
+	keyStyle := helpKeyStyle.Copy().Width(width + 2)
+
+	b := strings.Builder{}
+
+	b.WriteString(helpTitleStyle.Render("Keys"))
+	b.WriteString("\n\n")
+
+	lines := []string{}
+	for _, binding := range bindings {
+		if !binding.Enabled() {
+			continue
+		}
+
+		lines = append(lines, keyStyle.Render(binding.Help().Key)+helpDescStyle.Render(binding.Help().Desc))
+	}
+	b.WriteString(strings.Join(lines, "\n"))
+
+	return b.String()
+}
+
+// setHelpContent puts the help into its viewport, scrolled when it is taller
+// than the terminal.
+func setHelpContent(m Model) Model {
+	content := renderHelp(helpBindings(m.keys, m.tableModel.KeyMap()))
+
+	height := lipgloss.Height(content)
+	if available := m.height - helpHintLines; m.height > 0 && height > available {
+		height = available
+		if height < 1 {
+			height = 1
+		}
+	}
+
+	m.helpView.Width = m.width
+	m.helpView.Height = height
+	m.helpView.SetContent(content)
+
+	return m
+}
+
+// renderHelpView renders the scrolled help and the keys closing it.
+func renderHelpView(m Model) string {
+	hint := m.keys.Back.Help().Key + "/" + m.keys.Help.Help().Key + ": close"
+	if !m.helpView.AtTop() || !m.helpView.AtBottom() {
+		hint += fmt.Sprintf(" · j/k: scroll (%.0f%%)", m.helpView.ScrollPercent()*100)
+	}
+
+	return m.helpView.View() + "\n\n" + helpHintStyle.Render(hint) + "\n"
+}
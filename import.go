@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+)
+
+const (
+	defaultImportPath = "tsk-import.jsonl"
+
+	// maxImportErrors is the number of failed lines named in the status line.
+	maxImportErrors = 3
+)
+
+// tasksImportedMsg is sent after a JSON lines file has been imported.
+type tasksImportedMsg struct {
+	path     string
+	imported int
+	errs     []string
+}
+
+// importTasks creates a task for every line of r holding a JSON object shaped
+// like entity.Task. Blank lines are skipped, invalid lines are collected as
+// errors and don't stop the import. A missing status makes the task todo.
+func importTasks(tr repository.TaskRepository, r io.Reader) (int, []string, error) {
+	imported := 0
+	errs := []string{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var task entity.Task
+		if err := json.Unmarshal([]byte(text), &task); err != nil {
+			errs = append(errs, fmt.Sprintf("line %d: %s", line, err))
+			continue
+		}
+
+		if err := validateImportedTask(&task); err != nil {
+			errs = append(errs, fmt.Sprintf("line %d: %s", line, err))
+			continue
+		}
+
+		if _, err := restoreTask(tr, task); err != nil {
+			errs = append(errs, fmt.Sprintf("line %d: %s", line, err))
+			continue
+		}
+
+		imported++
+	}
+
+	return imported, errs, scanner.Err()
+}
+
+func validateImportedTask(task *entity.Task) error {
+	task.Title = strings.TrimSpace(task.Title)
+	if task.Title == "" {
+		return fmt.Errorf("title must not be empty")
+	}
+
+	if task.Status == entity.TaskStatusNone {
+		task.Status = entity.TaskStatusTodo
+	}
+	if _, ok := entity.TaskStatusToString[task.Status]; !ok {
+		return fmt.Errorf("invalid status %d", task.Status)
+	}
+	if _, ok := entity.TaskPriorityToString[task.Priority]; !ok {
+		return fmt.Errorf("invalid priority %d", task.Priority)
+	}
+
+	return nil
+}
+
+// importFile imports the tasks of the JSON lines file at path.
+func importFile(tr repository.TaskRepository, path string) (int, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	return importTasks(tr, f)
+}
+
+func importTasksFrom(tr repository.TaskRepository) func(string) (tea.Cmd, error) {
+	return func(value string) (tea.Cmd, error) {
+		path := strings.TrimSpace(value)
+		if path == "" {
+			return nil, fmt.Errorf("path must not be empty")
+		}
+
+		return retryable(func() tea.Msg {
+			imported, errs, err := importFile(tr, path)
+			if err != nil && imported == 0 {
+				return errMsg{err: fmt.Errorf("failed to import tasks: %w", err)}
+			}
+			if err != nil {
+				errs = append(errs, err.Error())
+			}
+
+			return tasksImportedMsg{path: path, imported: imported, errs: errs}
+		}), nil
+	}
+}
+
+// importSummary reports the number of imported tasks and names the first
+// failed lines.
+func importSummary(msg tasksImportedMsg) string {
+	summary := fmt.Sprintf("imported %d tasks from %s", msg.imported, msg.path)
+	if len(msg.errs) == 0 {
+		return summary
+	}
+
+	summary += fmt.Sprintf(", %d failed", len(msg.errs))
+
+	errs := msg.errs
+	if len(errs) > maxImportErrors {
+		errs = append(errs[:maxImportErrors:maxImportErrors], "…")
+	}
+
+	return summary + " (" + strings.Join(errs, "; ") + ")"
+}
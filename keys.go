@@ -0,0 +1,428 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/evertras/bubble-table/table"
+)
+
+// keyMap holds the key bindings of the task table, the navigation keys are
+// part of the table's own key map.
+type keyMap struct {
+	Detail          key.Binding
+	New             key.Binding
+	QuickAdd        key.Binding
+	EditTitle       key.Binding
+	AddNote         key.Binding
+	AdvanceStatus   key.Binding
+	PickStatus      key.Binding
+	MarkDone        key.Binding
+	RaisePriority   key.Binding
+	LowerPriority   key.Binding
+	PickPriority    key.Binding
+	SnoozeDay       key.Binding
+	SnoozeWeek      key.Binding
+	ClearDue        key.Binding
+	Delete          key.Binding
+	ClearDone       key.Binding
+	Undo            key.Binding
+	FilterStatus    key.Binding
+	FilterPriority  key.Binding
+	FilterLow       key.Binding
+	FilterMedium    key.Binding
+	FilterHigh      key.Binding
+	FilterTag       key.Binding
+	FilterDue       key.Binding
+	FilterToday     key.Binding
+	FilterCreated   key.Binding
+	ClearFilters    key.Binding
+	Search          key.Binding
+	JumpToID        key.Binding
+	Copy            key.Binding
+	LastRow         key.Binding
+	SortColumn      key.Binding
+	SortDirection   key.Binding
+	ToggleTime      key.Binding
+	ToggleHeader    key.Binding
+	TogglePanel     key.Binding
+	ToggleStats     key.Binding
+	ToggleGroups    key.Binding
+	ToggleDueGroups key.Binding
+	ToggleDense     key.Binding
+	ToggleList      key.Binding
+	TogglePin       key.Binding
+	Archive         key.Binding
+	ArchiveView     key.Binding
+	Focus           key.Binding
+	SwitchDB        key.Binding
+	Columns         key.Binding
+	CycleTheme      key.Binding
+	Import          key.Binding
+	ExportCSV       key.Binding
+	ExportJSON      key.Binding
+	Refresh         key.Binding
+	Retry           key.Binding
+	Help            key.Binding
+	ChangeLog       key.Binding
+	Back            key.Binding
+	Quit            key.Binding
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{
+		Detail:          key.NewBinding(key.WithKeys("enter", "l"), key.WithHelp("enter/l", "show task details")),
+		New:             key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "create a new task")),
+		QuickAdd:        key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "quickly add tasks by title")),
+		EditTitle:       key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit the task title")),
+		AddNote:         key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "add a note to the task")),
+		AdvanceStatus:   key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "advance the task status")),
+		PickStatus:      key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "pick the status")),
+		MarkDone:        key.NewBinding(key.WithKeys("D"), key.WithHelp("D", "mark the selected tasks done")),
+		RaisePriority:   key.NewBinding(key.WithKeys("+"), key.WithHelp("+", "raise the priority")),
+		LowerPriority:   key.NewBinding(key.WithKeys("-"), key.WithHelp("-", "lower the priority")),
+		PickPriority:    key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pick the priority")),
+		SnoozeDay:       key.NewBinding(key.WithKeys("z"), key.WithHelp("z", "push the due date by a day")),
+		SnoozeWeek:      key.NewBinding(key.WithKeys("Z"), key.WithHelp("Z", "push the due date by a week")),
+		ClearDue:        key.NewBinding(key.WithKeys("U"), key.WithHelp("U", "remove the due date")),
+		Delete:          key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete the selected tasks")),
+		ClearDone:       key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "delete all done tasks")),
+		Undo:            key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "undo the last delete or status change")),
+		FilterStatus:    key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "cycle the status filter")),
+		FilterPriority:  key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "cycle the priority filter")),
+		FilterLow:       key.NewBinding(key.WithKeys("1"), key.WithHelp("1", "toggle showing only low priority tasks")),
+		FilterMedium:    key.NewBinding(key.WithKeys("2"), key.WithHelp("2", "toggle showing only medium priority tasks")),
+		FilterHigh:      key.NewBinding(key.WithKeys("3"), key.WithHelp("3", "toggle showing only high priority tasks")),
+		FilterTag:       key.NewBinding(key.WithKeys("#"), key.WithHelp("#", "cycle the #tag filter")),
+		FilterDue:       key.NewBinding(key.WithKeys("@"), key.WithHelp("@", "toggle showing only tasks with a due date")),
+		FilterToday:     key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "toggle showing only the unfinished tasks due today")),
+		FilterCreated:   key.NewBinding(key.WithKeys("W"), key.WithHelp("W", "filter by creation date")),
+		ClearFilters:    key.NewBinding(key.WithKeys("c", "esc"), key.WithHelp("c/esc", "clear the filters, search and sort")),
+		Search:          key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search task titles, n: searches the notes too")),
+		JumpToID:        key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "go to a task by ID")),
+		Copy:            key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy the task to the clipboard")),
+		LastRow:         key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "go to the last task")),
+		SortColumn:      key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "cycle the sort column")),
+		SortDirection:   key.NewBinding(key.WithKeys("O"), key.WithHelp("O", "toggle the sort direction")),
+		ToggleTime:      key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "toggle relative creation and update times")),
+		ToggleHeader:    key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "toggle the table header")),
+		TogglePanel:     key.NewBinding(key.WithKeys("|"), key.WithHelp("|", "toggle the details panel")),
+		ToggleStats:     key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "toggle the tasks created per day")),
+		ToggleGroups:    key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "group the tasks by status")),
+		ToggleDueGroups: key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "group the tasks by due date")),
+		ToggleDense:     key.NewBinding(key.WithKeys("="), key.WithHelp("=", "toggle the dense table")),
+		ToggleList:      key.NewBinding(key.WithKeys("V"), key.WithHelp("V", "toggle the list of one line per task")),
+		TogglePin:       key.NewBinding(key.WithKeys("*"), key.WithHelp("*", "pin / unpin the task to the top")),
+		Archive:         key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "archive / unarchive the task")),
+		ArchiveView:     key.NewBinding(key.WithKeys("B"), key.WithHelp("B", "toggle showing the archived tasks")),
+		Focus:           key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "focus on the task in progress")),
+		SwitchDB:        key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "open another profile or database")),
+		Columns:         key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "show / hide columns")),
+		CycleTheme:      key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "switch to the next theme preset")),
+		Import:          key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "import tasks from a JSON lines file")),
+		ExportCSV:       key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "export the listed tasks to CSV")),
+		ExportJSON:      key.NewBinding(key.WithKeys("X"), key.WithHelp("X", "export the listed tasks to JSON")),
+		Refresh:         key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "reload the tasks")),
+		Retry:           key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "retry the last failed action")),
+		Help:            key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "toggle this help")),
+		ChangeLog:       key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "toggle the changes made this session")),
+		Back:            key.NewBinding(key.WithKeys("esc", "h"), key.WithHelp("esc/h", "close the details or this help")),
+		Quit:            key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q/ctrl+c", "quit")),
+	}
+}
+
+func defaultTableKeyMap() table.KeyMap {
+	keys := table.DefaultKeyMap()
+
+	keys.RowDown.SetKeys("j", "down", "s")
+	keys.RowDown.SetHelp("j/↓", "move down")
+	keys.RowUp.SetKeys("k", "up", "w")
+	keys.RowUp.SetHelp("k/↑", "move up")
+	keys.PageDown.SetKeys("pgdown", "right")
+	keys.PageDown.SetHelp("pgdown/→", "next page")
+	keys.PageUp.SetKeys("pgup", "left")
+	keys.PageUp.SetHelp("pgup/←", "previous page")
+	keys.PageFirst.SetKeys("home")
+	keys.PageFirst.SetHelp("home", "first page")
+	keys.PageLast.SetKeys("end")
+	keys.PageLast.SetHelp("end", "last page")
+	keys.RowSelectToggle.SetKeys("v")
+	keys.RowSelectToggle.SetHelp("v", "select the task")
+	keys.Filter.Unbind()
+
+	return keys
+}
+
+// helpBindings lists the bindings shown in the help overlay, in order.
+func helpBindings(k keyMap, t table.KeyMap) []key.Binding {
+	return []key.Binding{
+		t.RowDown,
+		t.RowUp,
+		t.PageDown,
+		t.PageUp,
+		t.PageFirst,
+		t.PageLast,
+		t.RowSelectToggle,
+		k.Detail,
+		k.New,
+		k.QuickAdd,
+		k.EditTitle,
+		k.AddNote,
+		k.AdvanceStatus,
+		k.PickStatus,
+		k.MarkDone,
+		k.RaisePriority,
+		k.LowerPriority,
+		k.PickPriority,
+		k.SnoozeDay,
+		k.SnoozeWeek,
+		k.ClearDue,
+		k.Delete,
+		k.ClearDone,
+		k.Undo,
+		k.FilterStatus,
+		k.FilterPriority,
+		k.FilterLow,
+		k.FilterMedium,
+		k.FilterHigh,
+		k.FilterTag,
+		k.FilterDue,
+		k.FilterToday,
+		k.FilterCreated,
+		k.ClearFilters,
+		k.Search,
+		k.JumpToID,
+		firstRowBinding(k),
+		k.LastRow,
+		k.Copy,
+		k.SortColumn,
+		k.SortDirection,
+		k.ToggleTime,
+		k.ToggleHeader,
+		k.TogglePanel,
+		k.ToggleStats,
+		k.ToggleGroups,
+		k.ToggleDueGroups,
+		k.ToggleDense,
+		k.ToggleList,
+		k.TogglePin,
+		k.Archive,
+		k.ArchiveView,
+		k.Focus,
+		k.SwitchDB,
+		k.Columns,
+		k.CycleTheme,
+		k.Import,
+		k.ExportCSV,
+		k.ExportJSON,
+		k.Refresh,
+		k.Retry,
+		k.ChangeLog,
+		k.Help,
+		k.Back,
+		k.Quit,
+	}
+}
+
+// mutatingBindings lists the bindings of the actions that change tasks, they
+// are disabled in read-only mode.
+func mutatingBindings(k keyMap) []key.Binding {
+	return []key.Binding{
+		k.New,
+		k.QuickAdd,
+		k.EditTitle,
+		k.AddNote,
+		k.AdvanceStatus,
+		k.PickStatus,
+		k.MarkDone,
+		k.RaisePriority,
+		k.LowerPriority,
+		k.PickPriority,
+		k.SnoozeDay,
+		k.SnoozeWeek,
+		k.ClearDue,
+		k.Delete,
+		k.ClearDone,
+		k.Undo,
+		k.Import,
+	}
+}
+
+// firstRowBinding describes the double press of the jump to ID key that
+// goes to the first task, for the help.
+func firstRowBinding(k keyMap) key.Binding {
+	keys := k.JumpToID.Keys()
+	if len(keys) == 0 {
+		return key.NewBinding(key.WithDisabled())
+	}
+
+	name := keyName(keys[0])
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(name+name, "go to the first task"))
+}
+
+// isGlobalQuit reports whether msg quits from everywhere, the forms and
+// prompts included. ctrl+c always does, and so do the ctrl keys bound to
+// quit, the other quit keys could be typed in a text field.
+func isGlobalQuit(k keyMap, msg tea.KeyMsg) bool {
+	if msg.String() == "ctrl+c" {
+		return true
+	}
+
+	return strings.HasPrefix(msg.String(), "ctrl+") && key.Matches(msg, k.Quit)
+}
+
+// keyConfig maps action names to the keys bound to them, e.g.
+// "quit": ["q", "ctrl+q"].
+type keyConfig map[string][]string
+
+type keyAction struct {
+	name    string
+	binding *key.Binding
+}
+
+// tableKeyActions lists the actions available while the task table is shown,
+// their keys must not overlap.
+func tableKeyActions(k *keyMap, t *table.KeyMap) []keyAction {
+	return []keyAction{
+		{"row_down", &t.RowDown},
+		{"row_up", &t.RowUp},
+		{"page_down", &t.PageDown},
+		{"page_up", &t.PageUp},
+		{"page_first", &t.PageFirst},
+		{"page_last", &t.PageLast},
+		{"select", &t.RowSelectToggle},
+		{"detail", &k.Detail},
+		{"new", &k.New},
+		{"quick_add", &k.QuickAdd},
+		{"edit_title", &k.EditTitle},
+		{"add_note", &k.AddNote},
+		{"advance_status", &k.AdvanceStatus},
+		{"pick_status", &k.PickStatus},
+		{"mark_done", &k.MarkDone},
+		{"raise_priority", &k.RaisePriority},
+		{"lower_priority", &k.LowerPriority},
+		{"pick_priority", &k.PickPriority},
+		{"snooze_day", &k.SnoozeDay},
+		{"snooze_week", &k.SnoozeWeek},
+		{"clear_due", &k.ClearDue},
+		{"delete", &k.Delete},
+		{"clear_done", &k.ClearDone},
+		{"undo", &k.Undo},
+		{"filter_status", &k.FilterStatus},
+		{"filter_priority", &k.FilterPriority},
+		{"filter_low", &k.FilterLow},
+		{"filter_medium", &k.FilterMedium},
+		{"filter_high", &k.FilterHigh},
+		{"filter_tag", &k.FilterTag},
+		{"filter_due", &k.FilterDue},
+		{"filter_today", &k.FilterToday},
+		{"filter_created", &k.FilterCreated},
+		{"clear_filters", &k.ClearFilters},
+		{"search", &k.Search},
+		{"jump_to_id", &k.JumpToID},
+		{"last_row", &k.LastRow},
+		{"copy", &k.Copy},
+		{"sort_column", &k.SortColumn},
+		{"sort_direction", &k.SortDirection},
+		{"toggle_time", &k.ToggleTime},
+		{"toggle_header", &k.ToggleHeader},
+		{"toggle_panel", &k.TogglePanel},
+		{"toggle_stats", &k.ToggleStats},
+		{"toggle_groups", &k.ToggleGroups},
+		{"toggle_due_groups", &k.ToggleDueGroups},
+		{"toggle_dense", &k.ToggleDense},
+		{"toggle_list", &k.ToggleList},
+		{"toggle_pin", &k.TogglePin},
+		{"archive", &k.Archive},
+		{"toggle_archive", &k.ArchiveView},
+		{"focus", &k.Focus},
+		{"switch_db", &k.SwitchDB},
+		{"columns", &k.Columns},
+		{"cycle_theme", &k.CycleTheme},
+		{"import", &k.Import},
+		{"export_csv", &k.ExportCSV},
+		{"export_json", &k.ExportJSON},
+		{"refresh", &k.Refresh},
+		{"retry", &k.Retry},
+		{"change_log", &k.ChangeLog},
+		{"help", &k.Help},
+		{"quit", &k.Quit},
+	}
+}
+
+// keyName returns the name of a key as used in the config file and the help.
+func keyName(k string) string {
+	if k == " " {
+		return "space"
+	}
+
+	return k
+}
+
+// applyKeyConfig rebinds the actions listed in cfg. Unknown actions, invalid
+// keys and keys bound to more than one action are reported as errors.
+func applyKeyConfig(k *keyMap, t *table.KeyMap, cfg keyConfig) error {
+	actions := tableKeyActions(k, t)
+	all := append(actions, keyAction{"back", &k.Back})
+
+	byName := make(map[string]*key.Binding, len(all))
+	for _, action := range all {
+		byName[action.name] = action.binding
+	}
+
+	for name, keys := range cfg {
+		binding, ok := byName[name]
+		if !ok {
+			return fmt.Errorf("unknown key action %q", name)
+		}
+
+		if len(keys) == 0 {
+			return fmt.Errorf("no keys given for %q", name)
+		}
+
+		bound := make([]string, len(keys))
+		names := make([]string, len(keys))
+
+		for i, k := range keys {
+			if k == "space" {
+				k = " "
+			}
+			if k == "" || (k != " " && strings.ContainsAny(k, " \t")) {
+				return fmt.Errorf("invalid key %q for %q", keys[i], name)
+			}
+
+			bound[i] = k
+			names[i] = keyName(k)
+		}
+
+		binding.SetKeys(bound...)
+		binding.SetHelp(strings.Join(names, "/"), binding.Help().Desc)
+	}
+
+	if err := checkKeyConflicts(actions); err != nil {
+		return err
+	}
+
+	// the back action is only used in the views over the table, where quit
+	// and the keys toggling the help and the change log are the only other
+	// actions
+	return checkKeyConflicts([]keyAction{{"back", &k.Back}, {"help", &k.Help}, {"change_log", &k.ChangeLog}, {"quit", &k.Quit}})
+}
+
+func checkKeyConflicts(actions []keyAction) error {
+	owners := map[string]string{}
+
+	for _, action := range actions {
+		for _, k := range action.binding.Keys() {
+			if owner, ok := owners[k]; ok && owner != action.name {
+				return fmt.Errorf("key %q is bound to both %q and %q", keyName(k), owner, action.name)
+			}
+
+			owners[k] = action.name
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/evertras/bubble-table/table"
+	"github.com/kakengloh/tsk/entity"
+)
+
+// tableFrameLines is the number of lines of the table borders and header,
+// the list view has none.
+const tableFrameLines = 4
+
+// the widths of the parts of the list lines after the title, so they line up:
+// "doing", "!medium" and "! 12d ago"
+const (
+	listStatusWidth   = 5
+	listPriorityWidth = 7
+	listDueWidth      = 9
+)
+
+// padRight pads s with spaces to width cells.
+func padRight(s string, width int) string {
+	if pad := width - lipgloss.Width(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+
+	return s
+}
+
+// cellText returns the text of a cell of the row data, rendered in its style
+// if styled is set.
+func cellText(cell interface{}, styled bool) string {
+	switch cell := cell.(type) {
+	case nil:
+		return ""
+	case table.StyledCell:
+		if styled {
+			return cell.Style.Render(fmt.Sprint(cell.Data))
+		}
+		return fmt.Sprint(cell.Data)
+	}
+
+	return fmt.Sprint(cell)
+}
+
+// listLine renders a task on a single line of width cells, like
+// "[ ] #3 Fix bug  todo  !high  in 2d", the ID padded to idWidth. The
+// highlighted line is rendered in the highlight style as a whole.
+func listLine(m Model, task entity.Task, highlighted bool, width int, idWidth int) string {
+	styled := !highlighted
+
+	check := "[ ]"
+	if m.selected[task.ID] {
+		check = "[x]"
+	}
+
+	id := fmt.Sprintf("#%d", task.ID)
+	status := entity.TaskStatusToString[task.Status]
+	priority := "!" + entity.TaskPriorityToString[task.Priority]
+	if styled {
+		id = idColumnStyle.Copy().Align(lipgloss.Left).Render(id)
+		status = cellText(statusCell(task.Status), true)
+		if style, ok := priorityStyles[task.Priority]; ok {
+			priority = style.Render(priority)
+		}
+	}
+
+	due := cellText(dueInCell(task, m.dueSoon), styled)
+	tail := "  " + padRight(status, listStatusWidth) + "  " + padRight(priority, listPriorityWidth) + "  " + padRight(due, listDueWidth)
+
+	prefix := ""
+	if m.pinned[task.ID] {
+		prefix = pinPrefix
+	}
+
+	search := m.search
+	if highlighted {
+		search = ""
+	}
+
+	head := check + " " + padRight(id, idWidth) + " "
+	titleWidth := width - lipgloss.Width(head) - lipgloss.Width(tail)
+	if titleWidth < 1 {
+		titleWidth = 1
+	}
+	title := taskTitle(task, search, prefix, titleWidth)
+	title = padRight(title, titleWidth)
+
+	line := head + title + tail
+	if highlighted {
+		return tableHighlightStyle.Copy().Width(width).MaxWidth(width).Render(line)
+	}
+
+	return lipgloss.NewStyle().MaxWidth(width).Render(line)
+}
+
+// renderTaskList renders the rows of the current page of the table one per
+// line, so the list has the filters, the sort, the pages and the cursor of the
+// table.
+func renderTaskList(m Model) string {
+	width := tableWidth(m)
+
+	tasks := make(map[int]entity.Task, len(m.tasks))
+	for _, task := range m.tasks {
+		tasks[task.ID] = task
+	}
+
+	rows := m.tableModel.GetVisibleRows()
+	highlighted := m.tableModel.GetHighlightedRowIndex()
+	start, end := m.tableModel.VisibleIndices()
+
+	idWidth := 0
+	for i := start; i <= end && i < len(rows); i++ {
+		if id, ok := rows[i].Data[columnKeyID].(int); ok && len(fmt.Sprintf("#%d", id)) > idWidth {
+			idWidth = len(fmt.Sprintf("#%d", id))
+		}
+	}
+
+	lines := []string{}
+	for i := start; i <= end && i < len(rows); i++ {
+		row := rows[i]
+
+		if isGroupHeader(row) {
+			lines = append(lines, groupHeaderStyle.Copy().MaxWidth(width).Render(cellText(row.Data[columnKeyTitle], false)))
+			continue
+		}
+
+		id, _ := row.Data[columnKeyID].(int)
+		if task, ok := tasks[id]; ok {
+			lines = append(lines, listLine(m, task, i == highlighted, width, idWidth))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+)
+
+// tasksLoadedMsg delivers the tasks listed by listTasks. Only the result of
+// the latest request (seq) is used, so a slow listing never overwrites a more
+// recent one.
+type tasksLoadedMsg struct {
+	seq       int
+	tasks     []entity.Task
+	total     int
+	err       error
+	highlight int
+	pageFirst bool
+}
+
+// listTasks lists the tasks in the background. highlight is the task to keep
+// the cursor on, pageFirst moves to the first page instead.
+func listTasks(tr repository.TaskRepository, filters entity.TaskFilters, seq int, highlight int, pageFirst bool) tea.Cmd {
+	return func() tea.Msg {
+		tasks, err := tr.ListTasksWithFilters(filters)
+		if err != nil {
+			err = fmt.Errorf("failed to list tasks: %w", err)
+		}
+
+		// the title shows how many tasks the filters hide
+		total := len(tasks)
+		if err == nil && filters != (entity.TaskFilters{}) {
+			var all []entity.Task
+			if all, err = tr.ListTasksWithFilters(entity.TaskFilters{}); err != nil {
+				err = fmt.Errorf("failed to count tasks: %w", err)
+			}
+			total = len(all)
+		}
+
+		return tasksLoadedMsg{
+			seq:       seq,
+			tasks:     tasks,
+			total:     total,
+			err:       err,
+			highlight: highlight,
+			pageFirst: pageFirst,
+		}
+	}
+}
+
+// loadTasks starts listing the tasks again, superseding a listing already in
+// flight. The highlighted task stays highlighted unless pageFirst is set.
+func loadTasks(m Model, pageFirst bool) (Model, tea.Cmd) {
+	id, _ := highlightedTaskID(m)
+
+	m.loadSeq++
+	cmd := listTasks(m.taskRepository, m.filters, m.loadSeq, id, pageFirst)
+
+	if !m.loading {
+		m.loading = true
+		cmd = tea.Batch(cmd, m.spinner.Tick)
+	}
+
+	return m, cmd
+}
+
+// tasksLoaded shows the listed tasks if they are the result of the latest
+// request.
+func tasksLoaded(m Model, msg tasksLoadedMsg) Model {
+	if msg.seq != m.loadSeq {
+		return m
+	}
+
+	m.loading = false
+
+	if msg.err != nil {
+		return setError(m, msg.err, reloadTasks)
+	}
+
+	m.all = msg.tasks
+	m.unfiltered = msg.total
+	m = updateRows(m)
+	m = setError(m, nil, nil)
+
+	if msg.pageFirst {
+		m.tableModel = m.tableModel.PageFirst()
+	} else {
+		m = highlightTask(m, msg.highlight)
+	}
+
+	return m
+}
+
+func newSpinner() spinner.Model {
+	s := spinner.New()
+	s.Spinner = spinner.MiniDot
+	s.Style = statusStyle
+
+	return s
+}
+
+// totalTasks returns the number of tasks in the database. Without filters it
+// follows the tasks added since they were listed.
+func totalTasks(m Model) int {
+	if m.filters == (entity.TaskFilters{}) {
+		return len(m.all)
+	}
+
+	return m.unfiltered
+}
+
+// togglePriorityFilter shows only the tasks of priority, or all of them again
+// if they are already filtered by it.
+func togglePriorityFilter(m Model, priority entity.TaskPriority) (Model, tea.Cmd) {
+	if m.filters.Priority == priority {
+		m.filters.Priority = entity.TaskPriorityNone
+	} else {
+		m.filters.Priority = priority
+	}
+
+	return loadTasks(m, false)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/xeonx/timeago"
+)
+
+// timeLocales are the languages of the relative times, by name and by code.
+var timeLocales = map[string]timeago.Config{
+	"english":    timeago.English,
+	"en":         timeago.English,
+	"portuguese": timeago.Portuguese,
+	"pt":         timeago.Portuguese,
+	"spanish":    timeago.Spanish,
+	"es":         timeago.Spanish,
+	"chinese":    timeago.Chinese,
+	"zh":         timeago.Chinese,
+	"french":     timeago.French,
+	"fr":         timeago.French,
+	"german":     timeago.German,
+	"de":         timeago.German,
+	"turkish":    timeago.Turkish,
+	"tr":         timeago.Turkish,
+	"korean":     timeago.Korean,
+	"ko":         timeago.Korean,
+}
+
+var (
+	// relativeFormat formats the relative times, like "3 hours ago".
+	relativeFormat = timeago.English
+
+	// elapsedFormat formats durations without the " ago" of the past.
+	elapsedFormat = withoutPast(timeago.English)
+)
+
+// timeLocale returns the relative time language of the name or code.
+func timeLocale(name string) (timeago.Config, bool) {
+	c, ok := timeLocales[strings.ToLower(strings.TrimSpace(name))]
+
+	return c, ok
+}
+
+// applyTimeLocale sets the language of the relative times everywhere.
+func applyTimeLocale(c timeago.Config) {
+	relativeFormat = c
+	elapsedFormat = withoutPast(c)
+}
+
+func withoutPast(c timeago.Config) timeago.Config {
+	c.PastPrefix = ""
+	c.PastSuffix = ""
+
+	return c
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+)
+
+// verbose logs the repository calls and the filters of every listing, set by
+// --verbose.
+var verbose bool
+
+// openLog makes the log go to the file at path, appending to it. The returned
+// function closes the file.
+func openLog(path string) (func(), error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	log.SetOutput(f)
+	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
+
+	return func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+		f.Close()
+	}, nil
+}
+
+// silenceLog discards the log, for the time the UI owns the terminal. The
+// returned function restores it.
+func silenceLog() func() {
+	log.SetOutput(io.Discard)
+
+	return func() { log.SetOutput(os.Stderr) }
+}
+
+// logEvent logs an event with its key value pairs, the values quoted when
+// needed: rows filters=status=todo search="a b" listed=3.
+func logEvent(event string, kv ...interface{}) {
+	b := strings.Builder{}
+	b.WriteString(event)
+
+	for i := 0; i+1 < len(kv); i += 2 {
+		value := fmt.Sprint(kv[i+1])
+		if strings.ContainsAny(value, " \"=") || value == "" {
+			value = fmt.Sprintf("%q", value)
+		}
+		fmt.Fprintf(&b, " %s=%s", kv[i], value)
+	}
+
+	log.Print(b.String())
+}
+
+func filtersString(f entity.TaskFilters) string {
+	parts := []string{}
+
+	if f.Status != entity.TaskStatusNone {
+		parts = append(parts, "status="+entity.TaskStatusToString[f.Status])
+	}
+	if f.Priority != entity.TaskPriorityNone {
+		parts = append(parts, "priority="+entity.TaskPriorityToString[f.Priority])
+	}
+	if f.Due != 0 {
+		parts = append(parts, "due="+f.Due.String())
+	}
+	if f.Keyword != "" {
+		parts = append(parts, "keyword="+f.Keyword)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// loggedRepository logs the calls of the repository it wraps.
+type loggedRepository struct {
+	repository.TaskRepository
+}
+
+// logRepository wraps tr to log its calls when verbose.
+func logRepository(tr repository.TaskRepository) repository.TaskRepository {
+	if !verbose {
+		return tr
+	}
+
+	return loggedRepository{tr}
+}
+
+// unwrapRepository returns the repository tr logs or tracks the calls of, or
+// tr.
+func unwrapRepository(tr repository.TaskRepository) repository.TaskRepository {
+	for {
+		switch wrapper := tr.(type) {
+		case loggedRepository:
+			tr = wrapper.TaskRepository
+		case trackedRepository:
+			tr = wrapper.TaskRepository
+		default:
+			return tr
+		}
+	}
+}
+
+func logCall(call string, start time.Time, err error, kv ...interface{}) {
+	kv = append([]interface{}{"call", call}, kv...)
+	kv = append(kv, "took", time.Since(start).Round(time.Microsecond), "err", err)
+	logEvent("repository", kv...)
+}
+
+func (r loggedRepository) CreateTask(title string, priority entity.TaskPriority, status entity.TaskStatus, due time.Time, note string) (entity.Task, error) {
+	start := time.Now()
+	task, err := r.TaskRepository.CreateTask(title, priority, status, due, note)
+	logCall("CreateTask", start, err, "id", task.ID)
+
+	return task, err
+}
+
+func (r loggedRepository) ListTasks(ids ...int) (entity.TaskList, error) {
+	start := time.Now()
+	tasks, err := r.TaskRepository.ListTasks(ids...)
+	logCall("ListTasks", start, err, "ids", ids, "tasks", len(tasks))
+
+	return tasks, err
+}
+
+func (r loggedRepository) ListTasksWithFilters(filters entity.TaskFilters) (entity.TaskList, error) {
+	start := time.Now()
+	tasks, err := r.TaskRepository.ListTasksWithFilters(filters)
+	logCall("ListTasksWithFilters", start, err, "filters", filtersString(filters), "tasks", len(tasks))
+
+	return tasks, err
+}
+
+func (r loggedRepository) GetTaskByID(id int) (entity.Task, error) {
+	start := time.Now()
+	task, err := r.TaskRepository.GetTaskByID(id)
+	logCall("GetTaskByID", start, err, "id", id)
+
+	return task, err
+}
+
+func (r loggedRepository) UpdateTask(id int, data entity.Task) (entity.Task, error) {
+	start := time.Now()
+	task, err := r.TaskRepository.UpdateTask(id, data)
+	logCall("UpdateTask", start, err, "id", id)
+
+	return task, err
+}
+
+func (r loggedRepository) UpdateTaskStatus(status entity.TaskStatus, ids ...int) []repository.UpdateTaskStatusResult {
+	start := time.Now()
+	results := r.TaskRepository.UpdateTaskStatus(status, ids...)
+
+	var err error
+	for _, res := range results {
+		if res.Err != nil {
+			err = res.Err
+		}
+	}
+	logCall("UpdateTaskStatus", start, err, "status", entity.TaskStatusToString[status], "ids", ids)
+
+	return results
+}
+
+func (r loggedRepository) DeleteTask(ids ...int) error {
+	start := time.Now()
+	err := r.TaskRepository.DeleteTask(ids...)
+	logCall("DeleteTask", start, err, "ids", ids)
+
+	return err
+}
+
+func (r loggedRepository) AddNotes(id int, notes ...string) (entity.Task, error) {
+	start := time.Now()
+	task, err := r.TaskRepository.AddNotes(id, notes...)
+	logCall("AddNotes", start, err, "id", id, "notes", len(notes))
+
+	return task, err
+}
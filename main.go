@@ -1,27 +1,36 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/evertras/bubble-table/table"
-	"github.com/kakengloh/tsk/driver"
 	"github.com/kakengloh/tsk/entity"
 	"github.com/kakengloh/tsk/repository"
-	"github.com/xeonx/timeago"
+	"github.com/muesli/termenv"
 )
 
 const (
+	columnKeyIcon     = "icon"
 	columnKeyID       = "id"
 	columnKeyTitle    = "title"
 	columnKeyStatus   = "status"
 	columnKeyPriority = "priority"
 	columnKeyCreated  = "created"
+	columnKeyUpdated  = "updated"
 	columnKeyDueDate  = "due_date"
+	columnKeyDueIn    = "due_in"
 	columnKeyNotes    = "notes"
 )
 
@@ -45,150 +54,1776 @@ var (
 
 		InnerDivider: "║",
 	}
+
+	// denseBorder keeps the frame of the table but blanks the dividers
+	// between the columns.
+	denseBorder = table.Border{
+		Top:    "─",
+		Left:   "│",
+		Right:  "│",
+		Bottom: "─",
+
+		TopRight:    "╮",
+		TopLeft:     "╭",
+		BottomRight: "╯",
+		BottomLeft:  "╰",
+
+		TopJunction:    "─",
+		LeftJunction:   "├",
+		RightJunction:  "┤",
+		BottomJunction: "─",
+		InnerJunction:  "─",
+
+		InnerDivider: " ",
+	}
 )
 
-func taskDueAsString(task entity.Task) string {
+// denseHiddenColumns are hidden in dense mode on top of the hidden columns.
+var denseHiddenColumns = []string{columnKeyNotes, columnKeyCreated}
+
+// isOverdue reports whether the task is past its due date and not done yet.
+func isOverdue(task entity.Task) bool {
+	return !task.Due.IsZero() && !time.Now().Before(task.Due) && task.Status != entity.TaskStatusDone
+}
+
+// isDueSoon reports whether the task is not done and due within the given
+// duration.
+func isDueSoon(task entity.Task, within time.Duration) bool {
+	if task.Due.IsZero() || task.Status == entity.TaskStatusDone {
+		return false
+	}
+
+	until := time.Until(task.Due)
+
+	return until > 0 && until <= within
+}
+
+// isDueToday reports whether an unfinished task is due between the last and
+// the next local midnight.
+func isDueToday(task entity.Task, now time.Time) bool {
+	if task.Due.IsZero() || task.Status == entity.TaskStatusDone {
+		return false
+	}
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	due := task.Due.In(now.Location())
+
+	return !due.Before(midnight) && due.Before(midnight.AddDate(0, 0, 1))
+}
+
+// taskDueAsString formats the due date, relative if it is in the past or
+// closer than relative.
+func taskDueAsString(task entity.Task, format string, relative time.Duration) string {
 	due := ""
 
 	if !task.Due.IsZero() {
 		if time.Now().Before(task.Due) {
-			if time.Until(task.Due) < 24*time.Hour {
-				due = timeago.English.Format(task.Due)
+			if time.Until(task.Due) < relative {
+				due = relativeFormat.Format(task.Due)
 			} else {
-				due = task.Due.Format("2006-01-02 15:04:05")
+				due = task.Due.Format(format)
 			}
 		} else {
-			due = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#cc241d")).
-				Render(timeago.English.Format(task.Due))
+			due = relativeFormat.Format(task.Due)
 		}
 	}
 
 	return due
 }
 
-type Model struct {
-	taskRepository repository.TaskRepository
-	tableModel     table.Model
+// titleLines is the number of lines of the title above the table.
+const titleLines = 1
+
+// reservedLines is the number of lines used by the title, the table borders,
+// header and the footer lines below the table.
+const reservedLines = 8 + titleLines
+
+// tablePageSize returns the fixed page size if one is set, or the number of
+// rows fitting the terminal.
+func tablePageSize(m Model) int {
+	if m.pageSize > 0 {
+		return m.pageSize
+	}
+
+	reserved := reservedLines
+	if m.stats {
+		reserved += statsPanelLines
+	}
+	if m.list {
+		reserved -= tableFrameLines
+	}
+
+	if pageSize := m.height - reserved; pageSize > 0 {
+		return pageSize
+	}
+
+	return 1
+}
+
+// taskTimeAsString formats the creation or update time of a task, relative
+// if it is less than a day ago and relative is set.
+func taskTimeAsString(t time.Time, format string, relative bool) string {
+	if relative && time.Since(t) < 24*time.Hour {
+		return relativeFormat.Format(t)
+	}
+
+	return t.Format(format)
+}
+
+// taskCounts summarizes the listed tasks.
+type taskCounts struct {
+	total   int
+	done    int
+	overdue int
 }
 
-func NewModel(tr repository.TaskRepository) Model {
-	columns := []table.Column{
-		table.NewColumn(columnKeyID, "ID", 5).WithStyle(
-			lipgloss.NewStyle().
-				Faint(true).
-				Foreground(lipgloss.Color("#fabd2f")).
-				Align(lipgloss.Center)),
-		table.NewColumn(columnKeyTitle, "Title", 20),
-		table.NewColumn(columnKeyStatus, "Status", 6),
-		table.NewColumn(columnKeyPriority, "Priority", 8),
-		table.NewColumn(columnKeyCreated, "Created", 19),
-		table.NewColumn(columnKeyDueDate, "Due Date", 19),
-		table.NewColumn(columnKeyNotes, "Notes", 16), // TODO FlexColumn
+func (c *taskCounts) add(task entity.Task) {
+	c.total++
+	if task.Status == entity.TaskStatusDone {
+		c.done++
 	}
+	if isOverdue(task) {
+		c.overdue++
+	}
+}
+
+type mode int
+
+const (
+	modeTable mode = iota
+	modeForm
+	modeConfirm
+	modePrompt
+	modeDetail
+	modeHelp
+	modeColumns
+	modeChoice
+	modeFocus
+	modeChanges
+)
+
+var (
+	infoStyle       = lipgloss.NewStyle().Faint(true)
+	emptyStateStyle = lipgloss.NewStyle().Faint(true).Padding(1, 2)
+)
+
+type Model struct {
+	taskRepository  repository.TaskRepository
+	tableModel      table.Model
+	keys            keyMap
+	all             []entity.Task
+	unfiltered      int
+	tasks           []entity.Task
+	counts          taskCounts
+	selected        map[int]bool
+	undo            []undoEntry
+	width           int
+	height          int
+	mode            mode
+	form            taskForm
+	confirm         confirmDialog
+	prompt          inputPrompt
+	columnPicker    columnPicker
+	choice          choicePicker
+	detail          entity.Task
+	detailView      viewport.Model
+	helpView        viewport.Model
+	filters         entity.TaskFilters
+	search          string
+	tag             string
+	dueOnly         bool
+	today           bool
+	created         createdRange
+	confirmQuit     bool
+	quitAt          time.Time // when quitting started to wait for the pending operations
+	ops             *opTracker
+	readOnly        bool
+	jumpPending     bool
+	sidePanel       bool
+	stats           bool
+	grouping        grouping
+	dense           bool
+	glyphs          glyphSet
+	list            bool
+	focusID         int
+	focusCursor     int
+	focusFilters    entity.TaskFilters
+	lastClickID     int
+	lastClickAt     time.Time
+	themePreset     string
+	configPreset    string
+	configTheme     Theme
+	bulk            bulkUpdate
+	tags            []string
+	relativeTime    bool
+	timeFormat      string
+	sortColumn      string
+	sortDesc        bool
+	tiebreakColumn  string
+	tiebreakDesc    bool
+	dbPath          string
+	dbName          string
+	profiles        map[string]string
+	statePath       string
+	pins            map[string][]int
+	pinned          map[int]bool
+	archive         map[string][]int
+	archived        map[int]bool
+	showArchived    bool
+	changes         []changeRecord
+	uncopied        []string // the tasks that couldn't be copied, printed on quit
+	closeDB         func()
+	server          *taskServer
+	configPath      string
+	hiddenColumns   map[string]bool
+	columnWidths    map[string]int
+	pageSize        int
+	dbModTime       time.Time
+	refreshInterval time.Duration
+	dueSoon         time.Duration
+	relativeDue     time.Duration
+	notifyOverdue   bool
+	notified        map[int]bool
+	overdueChecked  time.Time
+	loading         bool
+	loadSeq         int
+	restoreID       int
+	titleWidth      int                // the width of the title column, zero until it is known
+	startup         *startupCommandMsg // the outcome of the startup command, shown by Init
+	spinner         spinner.Model
+	progress        progress.Model
+	status          string
+	err             error
+	retry           tea.Cmd
+}
 
-	keys := table.DefaultKeyMap()
-	keys.RowDown.SetKeys("j", "down", "s")
-	keys.RowUp.SetKeys("k", "up", "w")
+func NewModel(tr repository.TaskRepository, cfg config) Model {
+	applyTheme(cfg.Theme)
+	applyTimeLocale(cfg.TimeLocale)
 
+	ops := &opTracker{}
 	model := Model{
-		tableModel: table.New(columns).
-			WithKeyMap(keys).
+		tableModel: table.New(buildColumns(0, defaultColumnWidths, validColumns(cfg.HiddenColumns))).
+			WithKeyMap(cfg.TableKeys).
 			Focused(true).
 			Border(customBorder).
-			HeaderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("#83a598")).Bold(true)).
-			WithBaseStyle(
-				lipgloss.NewStyle().
-					BorderForeground(lipgloss.Color("#689d6a")).
-					Foreground(lipgloss.Color("#b8bb26")).
-					Align(lipgloss.Left),
-			).
-			HighlightStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("#fabd2f")).Background(lipgloss.Color("#3c3836"))).
-			SortByAsc(columnKeyID),
-		taskRepository: tr,
+			HeaderStyle(tableHeaderStyle).
+			WithBaseStyle(tableBaseStyle).
+			WithFooterVisibility(false).
+			SelectableRows(true).
+			HighlightStyle(tableHighlightStyle),
+		taskRepository:  trackRepository(tr, ops),
+		ops:             ops,
+		keys:            cfg.Keys,
+		filters:         cfg.Filters,
+		sortColumn:      columnKeyID,
+		tiebreakColumn:  cfg.TiebreakColumn,
+		tiebreakDesc:    cfg.TiebreakDesc,
+		relativeTime:    true,
+		glyphs:          unicodeGlyphs,
+		timeFormat:      cfg.TimeFormat,
+		dbPath:          cfg.DBPath,
+		dbName:          cfg.DBName,
+		profiles:        cfg.Profiles,
+		statePath:       cfg.StatePath,
+		configPath:      cfg.ConfigPath,
+		hiddenColumns:   validColumns(cfg.HiddenColumns),
+		columnWidths:    validColumnWidths(cfg.ColumnWidths),
+		pageSize:        cfg.PageSize,
+		dbModTime:       dbModTime(cfg.DBPath),
+		refreshInterval: cfg.RefreshInterval,
+		notifyOverdue:   cfg.NotifyOverdue,
+		overdueChecked:  time.Now(),
+		dueSoon:         cfg.DueSoon,
+		relativeDue:     cfg.RelativeDue,
+		readOnly:        cfg.ReadOnly,
+		spinner:         newSpinner(),
+		progress:        newProgress(cfg.Theme),
+		themePreset:     cfg.ThemePreset,
+		configPreset:    cfg.ThemePreset,
+		configTheme:     cfg.Theme,
+		loading:         true,
+		loadSeq:         1,
+	}
+
+	if cfg.StatePath != "" {
+		state, err := loadState(cfg.StatePath)
+		if err != nil {
+			log.Printf("failed to load the state from %s: %s", cfg.StatePath, err)
+		}
+		model = restoreState(model, state)
+	}
+
+	// a default sort in the config file wins over the one of the last session
+	if cfg.SortColumn != "" {
+		model.sortColumn = cfg.SortColumn
+		model.sortDesc = cfg.SortDesc
+	}
+
+	if cfg.ASCIIGlyphs {
+		model.glyphs = asciiGlyphs
+	}
+
+	return applySort(model)
+}
+
+// taskNotesCount shows the number of notes, the notes themselves are listed
+// in the detail view.
+func taskNotesCount(task entity.Task) string {
+	if len(task.Notes) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("📝 %d", len(task.Notes))
+}
+
+// noteSearchPrefix starts a search that matches the notes of the tasks too.
+const noteSearchPrefix = "n:"
+
+func matchesSearch(task entity.Task, search string) bool {
+	return searchMatch(task, search) != ""
+}
+
+// searchMatch tells where the search matches the task, "title" or the note
+// it was found in, or "" if it doesn't match.
+func searchMatch(task entity.Task, search string) string {
+	query := strings.ToLower(strings.TrimPrefix(search, noteSearchPrefix))
+
+	if strings.Contains(strings.ToLower(task.Title), query) {
+		return "title"
 	}
 
-	model = updateRows(model)
+	if strings.HasPrefix(search, noteSearchPrefix) {
+		for i, note := range task.Notes {
+			if strings.Contains(strings.ToLower(note), query) {
+				return fmt.Sprintf("note %d", i+1)
+			}
+		}
+	}
 
-	return model
+	return ""
 }
 
+// updateRows rebuilds the rows from the loaded tasks, applying the search and
+// the tag filter.
 func updateRows(m Model) Model {
 	rows := []table.Row{}
+	listed := []entity.Task{}
+	counts := taskCounts{}
+	selected := map[int]bool{}
 
-	// TODO task filters
-	tasks, err := m.taskRepository.ListTasksWithFilters(entity.TaskFilters{
-		Status:   0,
-		Priority: 0,
-	})
-	if err != nil {
-		log.Fatalf("failed to list tasks: %s", err)
+	m.tags = collectTags(m.all)
+	now := time.Now()
+
+	for _, task := range m.all {
+		if m.archived[task.ID] != m.showArchived {
+			continue
+		}
+		if m.search != "" && !matchesSearch(task, m.search) {
+			continue
+		}
+		if m.tag != "" && !hasTag(task, m.tag) {
+			continue
+		}
+		if m.dueOnly && task.Due.IsZero() {
+			continue
+		}
+		if m.today && !isDueToday(task, now) {
+			continue
+		}
+		if m.created.active() && !m.created.contains(task.CreatedAt) {
+			continue
+		}
+
+		listed = append(listed, task)
+		counts.add(task)
+
+		if m.selected[task.ID] {
+			selected[task.ID] = true
+		}
+
+		rows = append(rows, taskRow(m, task, now))
+	}
+
+	if m.grouping != groupNone {
+		rows = append(rows, groupHeaderRows(m.grouping, listed, now)...)
 	}
 
-	for _, task := range tasks {
-		rows = append(rows, table.NewRow(table.RowData{
-			columnKeyID:       fmt.Sprintf("%d", task.ID),
-			columnKeyTitle:    task.Title,
-			columnKeyStatus:   entity.TaskStatusToString[task.Status],
-			columnKeyPriority: entity.TaskPriorityToString[task.Priority],
-			columnKeyCreated:  task.CreatedAt.Format("2006-01-02 15:04:05"),
-			columnKeyDueDate:  taskDueAsString(task),
-			columnKeyNotes:    strings.Join(task.Notes, "\n"),
-		}))
+	if verbose {
+		logEvent("rows", "filters", filtersString(m.filters), "search", m.search, "tag", m.tag,
+			"due_only", m.dueOnly, "today", m.today, "created", m.created.label,
+			"loaded", len(m.all), "listed", len(listed))
 	}
 
+	m.tasks = listed
+	m.counts = counts
+	m.selected = selected
 	m.tableModel = m.tableModel.WithRows(rows)
 
 	return m
 }
 
-func (m Model) Init() tea.Cmd {
-	return nil
+// appendTask adds a newly created task to the table without listing all the
+// tasks again, unless the status or priority filter may hide it.
+func appendTask(m Model, task entity.Task) (Model, tea.Cmd) {
+	if m.filters != (entity.TaskFilters{}) {
+		return loadTasks(m, false)
+	}
+
+	id, ok := highlightedTaskID(m)
+
+	m.all = append(m.all, task)
+	m = updateRows(m)
+
+	if ok {
+		m = highlightTask(m, id)
+	}
+
+	return m, nil
 }
 
-func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var (
-		cmd  tea.Cmd
-		cmds []tea.Cmd
-	)
+// patchTask replaces an updated task in the loaded tasks and rebuilds the
+// rows from them without listing all the tasks again. The tasks are listed
+// again if the task isn't loaded, or if it may no longer match the filters.
+func patchTask(m Model, task entity.Task) (Model, tea.Cmd) {
+	if !matchesFilters(task, m.filters) {
+		return loadTasks(m, false)
+	}
 
-	m.tableModel, cmd = m.tableModel.Update(msg)
-	cmds = append(cmds, cmd)
+	for i := range m.all {
+		if m.all[i].ID != task.ID {
+			continue
+		}
 
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
-			cmds = append(cmds, tea.Quit)
+		id, ok := highlightedTaskID(m)
 
-		case "h":
-			m.tableModel = m.tableModel.WithHeaderVisibility(!m.tableModel.GetHeaderVisibility())
+		m.all[i] = task
+		m = updateRows(m)
+
+		if ok {
+			m = highlightTask(m, id)
 		}
+
+		return m, nil
 	}
 
-	return m, tea.Batch(cmds...)
+	return loadTasks(m, false)
 }
 
-func (m Model) View() string {
-	return m.tableModel.View() + "\n"
+// matchesFilters tells whether the task would still be listed with the status
+// and priority filters. Other filters are left to the repository.
+func matchesFilters(task entity.Task, filters entity.TaskFilters) bool {
+	if filters.Due != 0 || filters.Keyword != "" {
+		return false
+	}
+	if filters.Status != entity.TaskStatusNone && task.Status != filters.Status {
+		return false
+	}
+
+	return filters.Priority == entity.TaskPriorityNone || task.Priority == filters.Priority
 }
 
-func main() {
-	// Database
-	db, err := driver.NewBolt()
+// taskTitle returns the title shown in the table on a single line cut to
+// width, with the matches of the search emphasized and the prefix in front.
+// It is marked when the task is overdue and the theme uses symbols.
+func taskTitle(task entity.Task, search string, prefix string, width int) string {
+	if isOverdue(task) {
+		prefix += overduePrefix
+	}
+
+	// the prefix is never cut, the title gets at least one cell
+	if width > 0 {
+		width -= lipgloss.Width(prefix)
+		if width < 1 {
+			width = 1
+		}
+	}
+	title := highlightMatches(ellipsize(singleLine(task.Title), width), search)
+
+	return prefix + title
+}
+
+func taskRow(m Model, task entity.Task, now time.Time) table.Row {
+	prefix := ""
+	if m.pinned[task.ID] {
+		prefix = pinPrefix
+	}
+	title := taskTitle(task, m.search, prefix, m.titleWidth)
+
+	data := table.RowData{
+		columnKeyIcon:     iconCell(task, m.glyphs),
+		columnKeyID:       task.ID,
+		columnKeyTitle:    title,
+		columnKeyStatus:   statusCell(task.Status),
+		columnKeyPriority: priorityCell(task.Priority),
+		columnKeyCreated:  taskTimeAsString(task.CreatedAt, m.timeFormat, m.relativeTime),
+		columnKeyUpdated:  taskTimeAsString(task.UpdatedAt, m.timeFormat, m.relativeTime),
+		columnKeyDueDate:  dueCell(task, m.timeFormat, m.dueSoon, m.relativeDue),
+		columnKeyDueIn:    dueInCell(task, m.dueSoon),
+		columnKeyNotes:    taskNotesCount(task),
+	}
+
+	for key, value := range taskSortValues(task) {
+		data[key] = value
+	}
+	data[groupKey] = groupRank(m.grouping, task, now)
+	data[pinKey] = pinRank(m.pinned[task.ID])
+
+	row := table.NewRow(data)
+	if isOverdue(task) {
+		row = row.WithStyle(overdueRowStyle)
+	}
+	if m.selected[task.ID] {
+		row = row.Selected(true)
+	}
+
+	return row
+}
+
+// shownHiddenColumns returns the columns hidden from the table, the ones
+// hidden in dense mode included.
+func shownHiddenColumns(m Model) map[string]bool {
+	if !m.dense {
+		return m.hiddenColumns
+	}
+
+	hidden := make(map[string]bool, len(m.hiddenColumns)+len(denseHiddenColumns))
+	for column, h := range m.hiddenColumns {
+		hidden[column] = h
+	}
+	for _, column := range denseHiddenColumns {
+		hidden[column] = true
+	}
+
+	return hidden
+}
+
+// applyColumns rebuilds the visible columns for the current width. The rows
+// are rendered again when the width of the title column changes, the titles
+// are cut to it.
+func applyColumns(m Model) Model {
+	width := tableWidth(m)
+
+	border := customBorder
+	if m.dense {
+		border = denseBorder
+	}
+
+	m.tableModel = m.tableModel.
+		WithColumns(buildColumns(width, m.columnWidths, shownHiddenColumns(m))).
+		Border(border).
+		SelectableRows(true).
+		WithTargetWidth(width).
+		WithMaxTotalWidth(width)
+
+	if w := titleColumnWidth(width, m.columnWidths, shownHiddenColumns(m)); w != m.titleWidth {
+		m.titleWidth = w
+		m = refilter(m)
+	}
+
+	return m
+}
+
+func applySort(m Model) Model {
+	if m.grouping != groupNone {
+		m.tableModel = m.tableModel.SortByAsc(groupKey).ThenSortByAsc(pinKey)
+	} else {
+		m.tableModel = m.tableModel.SortByAsc(pinKey)
+	}
+
+	m.tableModel = thenSortBy(m.tableModel, m.sortColumn, m.sortDesc)
+
+	// the tiebreaker orders the tasks equal by the sort column, the ID keeps
+	// the order of the rest deterministic
+	if m.tiebreakColumn != "" && m.tiebreakColumn != m.sortColumn {
+		m.tableModel = thenSortBy(m.tableModel, m.tiebreakColumn, m.tiebreakDesc)
+	}
+	if m.sortColumn != columnKeyID && m.tiebreakColumn != columnKeyID {
+		m.tableModel = m.tableModel.ThenSortByAsc(sortKey(columnKeyID))
+	}
+
+	return m
+}
+
+func thenSortBy(t table.Model, column string, desc bool) table.Model {
+	if desc {
+		return t.ThenSortByDesc(sortKey(column))
+	}
+
+	return t.ThenSortByAsc(sortKey(column))
+}
+
+// resort sorts the rows again after a change of the sort, keeping the
+// highlighted task.
+func resort(m Model) Model {
+	id, _ := highlightedTaskID(m)
+
+	return highlightTask(applySort(m), id)
+}
+
+// titleLine tells how many of the tasks are listed.
+func titleLine(m Model) string {
+	return fmt.Sprintf("tskui — showing %d of %d tasks", len(m.tasks), totalTasks(m))
+}
+
+func footerSummary(m Model) string {
+	status := "all"
+	if m.filters.Status != entity.TaskStatusNone {
+		status = entity.TaskStatusToString[m.filters.Status]
+	}
+
+	priority := "all"
+	if m.filters.Priority != entity.TaskPriorityNone {
+		priority = entity.TaskPriorityToString[m.filters.Priority]
+	}
+
+	summary := fmt.Sprintf("%d tasks · %d done · %d overdue", m.counts.total, m.counts.done, m.counts.overdue)
+	summary += " · status: " + status + " · priority: " + priority
+
+	if m.tag != "" {
+		summary += " · tag: #" + m.tag
+	}
+
+	if m.dueOnly {
+		summary += " · due only"
+	}
+
+	if m.today {
+		summary += " · Today"
+	}
+
+	if m.created.active() {
+		summary += " · created: " + m.created.label
+	}
+
+	if m.showArchived {
+		summary += " · archive"
+	}
+
+	if m.readOnly {
+		summary += " · read-only"
+	}
+
+	if m.dbName != "" {
+		summary += " · db: " + m.dbName
+	}
+
+	if len(m.selected) > 0 {
+		summary += fmt.Sprintf(" · %d selected", len(m.selected))
+	}
+
+	if m.search != "" {
+		summary += fmt.Sprintf(" · search: %q (%d found)", m.search, len(m.tasks))
+	}
+
+	arrow := "↑"
+	if m.sortDesc {
+		arrow = "↓"
+	}
+	summary += " · sort: " + strings.ToLower(columnTitles[m.sortColumn]) + " " + arrow
+
+	if m.tableModel.MaxPages() > 1 {
+		summary += fmt.Sprintf(" · page %d/%d", m.tableModel.CurrentPage(), m.tableModel.MaxPages())
+	}
+
+	return summary
+}
+
+func setStatus(m Model, status string) Model {
+	m.status = status
+
+	return m
+}
+
+func setError(m Model, err error, retry tea.Cmd) Model {
 	if err != nil {
-		log.Fatalf("failed to connect to BoltDB: %s", err)
+		logEvent("error", "err", err, "retryable", retry != nil)
 	}
-	defer driver.CloseBolt()
 
-	// Task repository
-	tr, err := repository.NewBoltTaskRepository(db)
+	m.err = err
+	m.retry = retry
+
+	return m
+}
+
+func highlightedTaskID(m Model) (int, bool) {
+	id, ok := m.tableModel.HighlightedRow().Data[columnKeyID].(int)
+
+	return id, ok
+}
+
+// highlightedTask returns the listed task of the highlighted row.
+func highlightedTask(m Model) (entity.Task, bool) {
+	if id, ok := highlightedTaskID(m); ok {
+		for _, task := range m.tasks {
+			if task.ID == id {
+				return task, true
+			}
+		}
+	}
+
+	return entity.Task{}, false
+}
+
+// visibleTasks returns the listed tasks in the order they are displayed.
+func visibleTasks(m Model) []entity.Task {
+	byID := make(map[int]entity.Task, len(m.tasks))
+	for _, task := range m.tasks {
+		byID[task.ID] = task
+	}
+
+	tasks := []entity.Task{}
+	for _, row := range m.tableModel.GetVisibleRows() {
+		id, ok := row.Data[columnKeyID].(int)
+		if !ok {
+			continue
+		}
+		if task, ok := byID[id]; ok {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks
+}
+
+// selectedTaskIDs returns the IDs of the rows selected in the table.
+func selectedTaskIDs(m Model) map[int]bool {
+	selected := map[int]bool{}
+	for _, row := range m.tableModel.SelectedRows() {
+		if id, ok := row.Data[columnKeyID].(int); ok {
+			selected[id] = true
+		}
+	}
+
+	return selected
+}
+
+// targetTaskIDs returns the IDs a bulk action applies to: the selected tasks
+// in display order, or the highlighted task if nothing is selected.
+func targetTaskIDs(m Model) []int {
+	if len(m.selected) == 0 {
+		if id, ok := highlightedTaskID(m); ok {
+			return []int{id}
+		}
+
+		return nil
+	}
+
+	ids := []int{}
+	for _, task := range visibleTasks(m) {
+		if m.selected[task.ID] {
+			ids = append(ids, task.ID)
+		}
+	}
+
+	return ids
+}
+
+// highlightTask moves the cursor to the task with id. If it is no longer
+// listed, the cursor stays at the same row, the closest one to where the task
+// was.
+func highlightTask(m Model, id int) Model {
+	for i, row := range m.tableModel.GetVisibleRows() {
+		if row.Data[columnKeyID] == id {
+			m.tableModel = m.tableModel.WithHighlightedRow(i)
+			return m
+		}
+	}
+
+	// the rows may have been replaced, this keeps the page of the cursor
+	m.tableModel = m.tableModel.WithHighlightedRow(m.tableModel.GetHighlightedRowIndex())
+
+	return m
+}
+
+// refilter lists the rows again after a change of the search or of a filter
+// applied to the loaded tasks, keeping the highlighted task.
+func refilter(m Model) Model {
+	id, _ := highlightedTaskID(m)
+
+	return highlightTask(updateRows(m), id)
+}
+
+// showDetail opens the detail view of a task.
+func showDetail(m Model, id int) Model {
+	task, err := m.taskRepository.GetTaskByID(id)
 	if err != nil {
-		log.Fatalf("failed to initialize task repository: %s", err)
+		return setError(m, fmt.Errorf("failed to load task %d: %w", id, err), nil)
 	}
 
-	p := tea.NewProgram(NewModel(tr))
-	if err := p.Start(); err != nil {
-		log.Fatal(err)
+	m.mode = modeDetail
+	m.detail = task
+	m.detailView.SetYOffset(0)
+
+	return setDetailContent(m)
+}
+
+func (m Model) Init() tea.Cmd {
+	cmds := []tea.Cmd{
+		listTasks(m.taskRepository, m.filters, m.loadSeq, m.restoreID, false),
+		m.spinner.Tick,
+		refreshTick(m.refreshInterval),
+		elapsedTick(),
+	}
+
+	if m.startup != nil {
+		startup := *m.startup
+		cmds = append(cmds, func() tea.Msg { return startup })
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+
+	// the cursor never rests on the headers of the grouped view
+	if m, ok := model.(Model); ok && m.grouping != groupNone {
+		return skipGroupHeader(m, movingUp(m, msg)), cmd
+	}
+
+	return model, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd  tea.Cmd
+		cmds []tea.Cmd
+	)
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if gained, ok := focusReport(msg); ok {
+			if gained {
+				return terminalFocused(m)
+			}
+			return m, nil
+		}
+
+		if !m.quitAt.IsZero() {
+			return updateQuitting(m, msg)
+		}
+
+		if m.confirmQuit {
+			if isGlobalQuit(m.keys, msg) {
+				return m, tea.Quit
+			}
+			switch msg.String() {
+			case "y", "Y":
+				m.confirmQuit = false
+				return quit(m)
+			case "n", "N", "esc":
+				m.confirmQuit = false
+			}
+			return m, nil
+		}
+
+		if isGlobalQuit(m.keys, msg) {
+			if (m.mode == modeForm && m.form.dirty()) || (m.mode == modePrompt && m.prompt.dirty()) {
+				m.confirmQuit = true
+				return m, nil
+			}
+			// ctrl+c doesn't wait for the pending operations
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return quit(m)
+		}
+
+	case quitPollMsg:
+		return quitPolled(m)
+
+	case taskFormSubmitMsg:
+		m.mode = modeTable
+		return m, createTask(m.taskRepository, msg.title, msg.priority, msg.due, msg.note)
+
+	case taskFormCancelMsg:
+		m.mode = modeTable
+		return m, nil
+
+	case confirmDoneMsg, promptDoneMsg:
+		m.mode = modeTable
+		return m, nil
+
+	case columnsChangedMsg:
+		m.hiddenColumns = msg.hidden
+		m.columnWidths = msg.widths
+		return applyColumns(m), nil
+
+	case choiceDoneMsg:
+		m.mode = modeTable
+		return m, msg.action
+
+	case columnPickerDoneMsg:
+		m.mode = modeTable
+		if m.configPath == "" {
+			return m, nil
+		}
+
+		hidden := []string{}
+		for _, column := range columnOrder {
+			if msg.hidden[column] {
+				hidden = append(hidden, column)
+			}
+		}
+		if err := writeConfigValue(m.configPath, "hidden_columns", hidden); err != nil {
+			m = setError(m, fmt.Errorf("failed to save the columns: %w", err), nil)
+		}
+		if msg.resized {
+			if err := writeConfigValue(m.configPath, "column_widths", changedColumnWidths(msg.widths)); err != nil {
+				m = setError(m, fmt.Errorf("failed to save the column widths: %w", err), nil)
+			}
+		}
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m = applyColumns(m)
+
+		m.tableModel = m.tableModel.WithPageSize(tablePageSize(m))
+		m = setDetailContent(m)
+		m = setHelpContent(m)
+
+		// keep the page of the highlighted task in view
+		if id, ok := highlightedTaskID(m); ok {
+			m = highlightTask(m, id)
+		}
+
+	case elapsedTickMsg:
+		m = updateRows(m)
+		// the open details show how long the task has been in progress too
+		if m.mode == modeDetail {
+			m = setDetailContent(m)
+		}
+		return m, elapsedTick()
+
+	case refreshTickMsg:
+		m, cmd = refreshIfChanged(m)
+		cmds = append(cmds, cmd, refreshTick(m.refreshInterval))
+		m, cmd = notifyOverdue(m)
+		return m, tea.Batch(append(cmds, cmd)...)
+
+	case notifyFailedMsg:
+		m.notifyOverdue = false
+		return setError(m, msg.err, nil), nil
+
+	case tasksLoadedMsg:
+		return tasksLoaded(m, msg), nil
+
+	case spinner.TickMsg:
+		if !m.loading {
+			return m, nil
+		}
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tasksImportedMsg:
+		if msg.imported > 0 {
+			m = recordChange(m, fmt.Sprintf("%d tasks imported from %s", msg.imported, msg.path))
+		}
+		return loadTasks(setStatus(m, importSummary(msg)), false)
+
+	case startupCommandMsg:
+		return startupCommandDone(m, msg)
+
+	case taskCopiedMsg:
+		return flashStatus(m, fmt.Sprintf("copied task %d", msg.id))
+
+	case copyFailedMsg:
+		return copyFailed(m, msg), nil
+
+	case tasksExportedMsg:
+		return setStatus(m, fmt.Sprintf("exported %d tasks to %s", msg.count, msg.path)), nil
+
+	case clearStatusMsg:
+		if m.status == msg.status {
+			m = setStatus(m, "")
+		}
+		return m, nil
+
+	case errMsg:
+		return setError(m, msg.err, msg.retry), nil
+
+	case reloadMsg:
+		return loadTasks(m, false)
+
+	case taskDeletedMsg:
+		m = recordChange(m, fmt.Sprintf("task %d deleted", msg.id))
+		m = pushUndo(m, msg.undo)
+		return loadTasks(setStatus(m, fmt.Sprintf("deleted task %d", msg.id)), false)
+
+	case tasksDeletedMsg:
+		m = recordChange(m, fmt.Sprintf("%d tasks deleted", msg.count))
+		m = pushUndo(m, msg.undo)
+		m.selected = nil
+		return loadTasks(setStatus(m, fmt.Sprintf("deleted %d tasks", msg.count)), false)
+
+	case doneTasksListedMsg:
+		return confirmClearDone(m, msg)
+
+	case doneTasksClearedMsg:
+		return doneTasksCleared(m, msg)
+
+	case bulkStatusMsg:
+		return startBulkStatus(m, msg)
+
+	case bulkStepMsg:
+		return bulkStep(m, msg)
+
+	case undoneMsg:
+		m = recordChange(m, "undid "+msg.description)
+		m = remapUndo(m, msg.restored)
+		return loadTasks(setStatus(m, "undid "+msg.description), false)
+
+	case createdRangeMsg:
+		m.created = msg.r
+		return refilter(m), nil
+
+	case searchMsg:
+		m.search = msg.query
+		return refilter(m), nil
+
+	case taskUpdatedMsg:
+		m = recordChange(m, msg.change)
+		m = pushUndo(m, msg.undo)
+		return patchTask(m, msg.task)
+
+	case taskCreatedMsg:
+		m = recordChange(m, fmt.Sprintf("task %d created: %q", msg.task.ID, msg.task.Title))
+		return appendTask(m, msg.task)
+
+	case dbOpenedMsg:
+		return dbOpened(m, msg)
+
+	case jumpToTaskMsg:
+		return highlightTask(m, msg.id), nil
+	}
+
+	switch m.mode {
+	case modeForm:
+		m.form, cmd = m.form.Update(msg)
+		return m, cmd
+
+	case modeConfirm:
+		m.confirm, cmd = m.confirm.Update(msg)
+		return m, cmd
+
+	case modePrompt:
+		// a second press of the jump key right after the first one goes to
+		// the first task instead of asking for an ID
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && m.jumpPending {
+			m.jumpPending = false
+			if key.Matches(keyMsg, m.keys.JumpToID) {
+				m.mode = modeTable
+				m.tableModel = m.tableModel.PageFirst()
+				return m, nil
+			}
+		}
+
+		m.prompt, cmd = m.prompt.Update(msg)
+		return m, cmd
+
+	case modeColumns:
+		m.columnPicker, cmd = m.columnPicker.Update(msg)
+		return m, cmd
+
+	case modeChoice:
+		m.choice, cmd = m.choice.Update(msg)
+		return m, cmd
+
+	case modeDetail:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(msg, m.keys.Back):
+				m.mode = modeTable
+			case key.Matches(msg, m.keys.Quit):
+				return quit(m)
+			default:
+				m.detailView, cmd = m.detailView.Update(msg)
+			}
+		}
+		return m, cmd
+
+	case modeFocus:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return updateFocus(m, msg)
+		}
+		return m, nil
+
+	case modeChanges:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(msg, m.keys.Back), key.Matches(msg, m.keys.ChangeLog):
+				m.mode = modeTable
+			case key.Matches(msg, m.keys.Quit):
+				return quit(m)
+			}
+		}
+		return m, nil
+
+	case modeHelp:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch {
+			case key.Matches(msg, m.keys.Back), key.Matches(msg, m.keys.Help):
+				m.mode = modeTable
+			case key.Matches(msg, m.keys.Quit):
+				return quit(m)
+			default:
+				m.helpView, cmd = m.helpView.Update(msg)
+			}
+		}
+		return m, cmd
+	}
+
+	if msg, ok := msg.(tea.MouseMsg); ok {
+		return handleMouse(m, msg), nil
+	}
+
+	m.tableModel, cmd = m.tableModel.Update(msg)
+	cmds = append(cmds, cmd)
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		m = setStatus(m, "")
+
+		switch {
+		case key.Matches(msg, m.tableModel.KeyMap().RowSelectToggle):
+			m.selected = selectedTaskIDs(m)
+
+		case m.readOnly && key.Matches(msg, mutatingBindings(m.keys)...):
+			m, cmd = flashStatus(m, "read-only, tasks can't be changed")
+			cmds = append(cmds, cmd)
+
+		case key.Matches(msg, m.keys.Quit):
+			m, cmd = quit(m)
+			cmds = append(cmds, cmd)
+
+		case key.Matches(msg, m.keys.Retry):
+			if m.retry != nil {
+				cmds = append(cmds, m.retry)
+				m = setError(m, nil, nil)
+			}
+
+		case key.Matches(msg, m.keys.ToggleHeader):
+			m.tableModel = m.tableModel.WithHeaderVisibility(!m.tableModel.GetHeaderVisibility())
+
+		case key.Matches(msg, m.keys.SwitchDB):
+			name := m.dbName
+			if name == "" {
+				name = defaultProfile
+			}
+			m.mode = modePrompt
+			m.prompt = newInputPrompt("Open profile or database", name, switchDB(m.profiles, m.dbPath))
+			cmds = append(cmds, m.prompt.Init())
+
+		case key.Matches(msg, m.keys.Copy):
+			if task, ok := highlightedTask(m); ok {
+				cmds = append(cmds, copyTask(task, m.timeFormat))
+			}
+
+		case key.Matches(msg, m.keys.TogglePin):
+			return togglePin(m)
+
+		case key.Matches(msg, m.keys.Archive):
+			return toggleArchived(m)
+
+		case key.Matches(msg, m.keys.ArchiveView):
+			return toggleArchiveView(m)
+
+		case key.Matches(msg, m.keys.Focus):
+			return enterFocus(m)
+
+		case key.Matches(msg, m.keys.ToggleGroups):
+			m = toggleGrouping(m, groupStatus)
+
+		case key.Matches(msg, m.keys.ToggleDueGroups):
+			m = toggleGrouping(m, groupDue)
+
+		case key.Matches(msg, m.keys.TogglePanel):
+			m.sidePanel = !m.sidePanel
+			m = applyColumns(m)
+
+		case key.Matches(msg, m.keys.ToggleStats):
+			m.stats = !m.stats
+			m.tableModel = m.tableModel.WithPageSize(tablePageSize(m))
+
+		case key.Matches(msg, m.keys.ToggleDense):
+			m.dense = !m.dense
+			m = applyColumns(m)
+
+		case key.Matches(msg, m.keys.ToggleList):
+			m.list = !m.list
+			m.tableModel = m.tableModel.WithPageSize(tablePageSize(m))
+
+		case key.Matches(msg, m.keys.CycleTheme):
+			m = nextThemePreset(m)
+			m, cmd = flashStatus(m, "theme: "+m.themePreset)
+			cmds = append(cmds, cmd)
+
+		case key.Matches(msg, m.keys.Columns):
+			m.mode = modeColumns
+			m.columnPicker = newColumnPicker(m.hiddenColumns, m.columnWidths, m.titleWidth)
+
+		case key.Matches(msg, m.keys.Refresh):
+			m, cmd = refresh(m)
+			cmds = append(cmds, cmd)
+			m, cmd = flashStatus(m, "refreshed")
+			cmds = append(cmds, cmd)
+
+		case key.Matches(msg, m.keys.ToggleTime):
+			m.relativeTime = !m.relativeTime
+			m = updateRows(m)
+
+		case key.Matches(msg, m.keys.New):
+			m.mode = modeForm
+			m.form = newTaskForm()
+			cmds = append(cmds, m.form.Init())
+
+		case key.Matches(msg, m.keys.QuickAdd):
+			m.mode = modePrompt
+			m.prompt = newInputPrompt("Add", "", quickAddTask(m.taskRepository)).withRepeat()
+			cmds = append(cmds, m.prompt.Init())
+
+		case key.Matches(msg, m.keys.AdvanceStatus):
+			if id, ok := highlightedTaskID(m); ok {
+				cmds = append(cmds, advanceTaskStatus(m.taskRepository, id))
+			}
+
+		case key.Matches(msg, m.keys.MarkDone):
+			if ids := targetTaskIDs(m); len(ids) > 1 {
+				m.mode = modeConfirm
+				m.confirm = newConfirmDialog(fmt.Sprintf("Mark %d tasks as done?", len(ids)), setBulkStatus(ids, entity.TaskStatusDone))
+			} else if len(ids) == 1 {
+				cmds = append(cmds, setBulkStatus(ids, entity.TaskStatusDone))
+			}
+
+		case key.Matches(msg, m.keys.RaisePriority):
+			if id, ok := highlightedTaskID(m); ok {
+				cmds = append(cmds, shiftTaskPriority(m.taskRepository, id, 1))
+			}
+
+		case key.Matches(msg, m.keys.PickStatus):
+			if task, ok := highlightedTask(m); ok {
+				m.mode = modeChoice
+				m.choice = newStatusPicker(m.taskRepository, task)
+			}
+
+		case key.Matches(msg, m.keys.PickPriority):
+			if task, ok := highlightedTask(m); ok {
+				m.mode = modeChoice
+				m.choice = newPriorityPicker(m.taskRepository, task)
+			}
+
+		case key.Matches(msg, m.keys.LowerPriority):
+			if id, ok := highlightedTaskID(m); ok {
+				cmds = append(cmds, shiftTaskPriority(m.taskRepository, id, -1))
+			}
+
+		case key.Matches(msg, m.keys.SnoozeDay):
+			if id, ok := highlightedTaskID(m); ok {
+				cmds = append(cmds, snoozeTask(m.taskRepository, id, 24*time.Hour))
+			}
+
+		case key.Matches(msg, m.keys.SnoozeWeek):
+			if id, ok := highlightedTaskID(m); ok {
+				cmds = append(cmds, snoozeTask(m.taskRepository, id, 7*24*time.Hour))
+			}
+
+		case key.Matches(msg, m.keys.ClearDue):
+			if task, ok := highlightedTask(m); ok && !task.Due.IsZero() {
+				cmds = append(cmds, clearDue(m.taskRepository, task.ID))
+			}
+
+		case key.Matches(msg, m.keys.Detail):
+			if id, ok := highlightedTaskID(m); ok {
+				m = showDetail(m, id)
+			}
+
+		case key.Matches(msg, m.keys.EditTitle):
+			if id, ok := highlightedTaskID(m); ok {
+				task, err := m.taskRepository.GetTaskByID(id)
+				if err != nil {
+					m = setError(m, fmt.Errorf("failed to load task %d: %w", id, err), nil)
+					break
+				}
+				m.mode = modePrompt
+				m.prompt = newInputPrompt("Title", task.Title, renameTask(m.taskRepository, id))
+				cmds = append(cmds, m.prompt.Init())
+			}
+
+		case key.Matches(msg, m.keys.AddNote):
+			if id, ok := highlightedTaskID(m); ok {
+				m.mode = modePrompt
+				m.prompt = newInputPrompt("Note", "", addNote(m.taskRepository, id))
+				cmds = append(cmds, m.prompt.Init())
+			}
+
+		case key.Matches(msg, m.keys.FilterStatus):
+			m.filters.Status++
+			if m.filters.Status > entity.TaskStatusDone {
+				m.filters.Status = entity.TaskStatusNone
+			}
+			m, cmd = loadTasks(m, false)
+			cmds = append(cmds, cmd)
+
+		case key.Matches(msg, m.keys.FilterPriority):
+			m.filters.Priority++
+			if m.filters.Priority > entity.TaskPriorityHigh {
+				m.filters.Priority = entity.TaskPriorityNone
+			}
+			m, cmd = loadTasks(m, false)
+			cmds = append(cmds, cmd)
+
+		case key.Matches(msg, m.keys.FilterLow):
+			m, cmd = togglePriorityFilter(m, entity.TaskPriorityLow)
+			cmds = append(cmds, cmd)
+
+		case key.Matches(msg, m.keys.FilterMedium):
+			m, cmd = togglePriorityFilter(m, entity.TaskPriorityMedium)
+			cmds = append(cmds, cmd)
+
+		case key.Matches(msg, m.keys.FilterHigh):
+			m, cmd = togglePriorityFilter(m, entity.TaskPriorityHigh)
+			cmds = append(cmds, cmd)
+
+		case key.Matches(msg, m.keys.FilterTag):
+			m.tag = nextTag(m.tags, m.tag)
+			m = refilter(m)
+
+		case key.Matches(msg, m.keys.FilterDue):
+			m.dueOnly = !m.dueOnly
+			m = refilter(m)
+
+		case key.Matches(msg, m.keys.FilterToday):
+			m.today = !m.today
+			m = refilter(m)
+
+		case key.Matches(msg, m.keys.ClearFilters):
+			m.filters = entity.TaskFilters{}
+			m.search = ""
+			m.tag = ""
+			m.dueOnly = false
+			m.today = false
+			m.created = createdRange{}
+			m.showArchived = false
+			m.sortColumn = columnKeyID
+			m.sortDesc = false
+			m = resort(m)
+			m, cmd = loadTasks(m, false)
+			cmds = append(cmds, cmd)
+			m, cmd = flashStatus(m, "filters cleared")
+			cmds = append(cmds, cmd)
+
+		case key.Matches(msg, m.keys.SortColumn):
+			for i, column := range sortColumns {
+				if column == m.sortColumn {
+					m.sortColumn = sortColumns[(i+1)%len(sortColumns)]
+					break
+				}
+			}
+			m = resort(m)
+
+		case key.Matches(msg, m.keys.SortDirection):
+			m.sortDesc = !m.sortDesc
+			m = resort(m)
+
+		case key.Matches(msg, m.keys.Search):
+			m.mode = modePrompt
+			m.prompt = newInputPrompt("Search", m.search, func(string) (tea.Cmd, error) { return nil, nil }).
+				withChange(searchTasks).
+				withCancel(searchTasks(""))
+			cmds = append(cmds, m.prompt.Init())
+
+		case key.Matches(msg, m.keys.FilterCreated):
+			m.mode = modePrompt
+			m.prompt = newInputPrompt("Created (today, week, month, 30d or 2022-10-01..2022-10-14, empty for all)", m.created.value, filterCreated)
+			cmds = append(cmds, m.prompt.Init())
+
+		case key.Matches(msg, m.keys.JumpToID):
+			m.mode = modePrompt
+			m.prompt = newInputPrompt("Go to ID", "", jumpToTask(m.tasks))
+			m.jumpPending = true
+			cmds = append(cmds, m.prompt.Init())
+
+		case key.Matches(msg, m.keys.LastRow):
+			if rows := len(m.tableModel.GetVisibleRows()); rows > 0 {
+				m.tableModel = m.tableModel.WithHighlightedRow(rows - 1)
+			}
+
+		case key.Matches(msg, m.keys.Import):
+			m.mode = modePrompt
+			m.prompt = newInputPrompt("Import JSON lines from", defaultImportPath, importTasksFrom(m.taskRepository))
+			cmds = append(cmds, m.prompt.Init())
+
+		case key.Matches(msg, m.keys.ExportCSV):
+			format := m.timeFormat
+			writeTasks := func(path string, tasks []entity.Task) error {
+				return writeCSV(path, tasks, format)
+			}
+			m.mode = modePrompt
+			m.prompt = newInputPrompt("Export CSV to", defaultCSVExportPath, exportTasks(visibleTasks(m), writeTasks))
+			cmds = append(cmds, m.prompt.Init())
+
+		case key.Matches(msg, m.keys.ExportJSON):
+			m.mode = modePrompt
+			m.prompt = newInputPrompt("Export JSON to", defaultJSONExportPath, exportTasks(visibleTasks(m), writeJSON))
+			cmds = append(cmds, m.prompt.Init())
+
+		case key.Matches(msg, m.keys.Help):
+			m.mode = modeHelp
+			m.helpView.SetYOffset(0)
+			m = setHelpContent(m)
+
+		case key.Matches(msg, m.keys.ChangeLog):
+			m.mode = modeChanges
+
+		case key.Matches(msg, m.keys.Undo):
+			if len(m.undo) == 0 {
+				m = setStatus(m, "nothing to undo")
+				break
+			}
+			entry := m.undo[len(m.undo)-1]
+			m.undo = m.undo[:len(m.undo)-1]
+			cmds = append(cmds, undoOperation(m.taskRepository, entry))
+
+		case key.Matches(msg, m.keys.Delete):
+			if len(m.selected) > 0 {
+				ids := targetTaskIDs(m)
+				m.mode = modeConfirm
+				m.confirm = newConfirmDialog(fmt.Sprintf("Delete %d selected tasks?", len(ids)), deleteTasks(m.taskRepository, ids))
+			} else if id, ok := highlightedTaskID(m); ok {
+				m.mode = modeConfirm
+				m.confirm = newConfirmDialog(fmt.Sprintf("Delete task %d?", id), deleteTask(m.taskRepository, id))
+			}
+
+		case key.Matches(msg, m.keys.ClearDone):
+			cmds = append(cmds, listDoneTasks(m.taskRepository))
+		}
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// progressWidth is the width of the completion bar in the footer, including
+// the percentage.
+const progressWidth = 20
+
+// newProgress returns the bar showing the share of the listed tasks done.
+func newProgress(t Theme) progress.Model {
+	return progress.New(progress.WithSolidFill(t.StatusDone), progress.WithWidth(progressWidth))
+}
+
+// quitConfirmView renders the question asked when quitting would discard an
+// unsaved edit.
+func quitConfirmView() string {
+	return confirmStyle.Render("Discard unsaved changes and quit? [y/n]")
+}
+
+// emptyState returns the message shown instead of the rows when no task is
+// listed, or "" if there are tasks or they are still loading.
+func emptyState(m Model) string {
+	if len(m.tasks) > 0 || m.loading || m.err != nil {
+		return ""
+	}
+
+	if m.filters != (entity.TaskFilters{}) || m.search != "" || m.tag != "" || m.dueOnly || m.today || m.created.active() {
+		return fmt.Sprintf("No tasks match your filter, press %s to clear it.", m.keys.ClearFilters.Help().Key)
+	}
+
+	if m.showArchived {
+		return fmt.Sprintf("No archived tasks, press %s to go back.", m.keys.ArchiveView.Help().Key)
+	}
+
+	if m.readOnly {
+		return "No tasks yet."
+	}
+
+	return fmt.Sprintf("No tasks yet, press %s to add one.", m.keys.New.Help().Key)
+}
+
+func (m Model) View() string {
+	if m.mode == modeForm {
+		view := m.form.View() + "\n"
+		if m.confirmQuit {
+			view += quitConfirmView() + "\n"
+		}
+		return view
+	}
+
+	if m.mode == modeChanges {
+		return renderChangeLog(m.changes, m.height, m.keys.Back.Help().Key+"/"+m.keys.ChangeLog.Help().Key) + "\n"
+	}
+
+	if m.mode == modeHelp {
+		return renderHelpView(m)
+	}
+
+	if m.mode == modeFocus {
+		return renderFocus(m)
+	}
+
+	if m.mode == modeDetail {
+		return renderDetailView(m)
+	}
+
+	view := m.tableModel.View()
+	if m.list {
+		view = renderTaskList(m)
+	}
+	if panelShown(m) {
+		view = lipgloss.JoinHorizontal(lipgloss.Top, view, renderSidePanel(m, lipgloss.Height(view)))
+	}
+	view = titleStyle.Render(titleLine(m)) + "\n" + view + "\n"
+	if empty := emptyState(m); empty != "" {
+		view += emptyStateStyle.Render(empty) + "\n"
+	}
+	if m.stats {
+		view += renderStatsPanel(m) + "\n"
+	}
+	footer := infoStyle.Render(footerSummary(m))
+	if m.counts.total > 0 {
+		footer = m.progress.ViewAs(float64(m.counts.done)/float64(m.counts.total)) + " " + footer
+	}
+	if m.loading {
+		footer = m.spinner.View() + " " + footer
+	}
+	view += footer + "\n"
+
+	if m.mode == modeConfirm {
+		view += m.confirm.View() + "\n"
+	} else if m.mode == modePrompt {
+		view += m.prompt.View() + "\n"
+		if m.confirmQuit {
+			view += quitConfirmView() + "\n"
+		}
+	} else if m.mode == modeColumns {
+		view += m.columnPicker.View() + "\n"
+	} else if m.mode == modeChoice {
+		view += m.choice.View() + "\n"
+	} else if m.bulk.active() {
+		view += bulkProgress(m) + "\n"
+	} else if m.status != "" {
+		view += statusStyle.Render(m.status) + "\n"
+	}
+
+	if m.err != nil {
+		banner := m.err.Error()
+		if m.retry != nil {
+			banner += " (press " + m.keys.Retry.Help().Key + " to retry)"
+		}
+		view += errorBannerStyle.Render(banner) + "\n"
+	}
+
+	return view
+}
+
+func main() {
+	readOnly := flag.Bool("readonly", false, "disable every action that changes tasks")
+	printOnly := flag.Bool("print", false, "print the tasks and exit")
+	printArchived := flag.Bool("archived", false, "print the archived tasks too, needs --print")
+	printStatsOnly := flag.Bool("stats", false, "print the statistics of the tasks and exit")
+	noColor := flag.Bool("no-color", false, "print without colors")
+	filter := flag.String("filter", "", "show only the matching tasks, e.g. status=todo,priority=high")
+	sortBy := flag.String("sort", "", "sort by a column, e.g. due_date or due_date:desc")
+	dbFlag := flag.String("db", "", "open this profile of the config file or database file instead of the default one")
+	changesPath := flag.String("changes-file", "", "append the changes made in the session to this file on quit")
+	importPath := flag.String("import", "", "import the tasks of a JSON lines file and exit")
+	pageSize := flag.Int("page-size", 0, "show this many rows per page instead of filling the terminal")
+	serve := flag.String("serve", "", "serve the tasks as JSON at /tasks on this address while tskui runs, e.g. :8080")
+	logPath := flag.String("log", "", "append the log to this file while tskui runs")
+	verboseLog := flag.Bool("verbose", false, "log the repository calls and the filters too, needs --log")
+	wait := flag.Duration("wait", 0, "wait this long for the database when another program has it locked, e.g. 30s")
+	flag.Parse()
+
+	if *verboseLog && *logPath == "" {
+		log.Fatalf("--verbose needs --log, the log can't be shown while tskui runs")
+	}
+	verbose = *verboseLog
+	if *printArchived && !*printOnly {
+		log.Fatalf("--archived needs --print")
+	}
+
+	if *noColor {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("%s", err)
+	}
+	if *readOnly {
+		cfg.ReadOnly = true
+	}
+	if err := applyViewFlags(&cfg, *filter, *sortBy); err != nil {
+		log.Fatalf("%s", err)
+	}
+	if *pageSize < 0 {
+		log.Fatalf("invalid page size %d", *pageSize)
+	} else if *pageSize > 0 {
+		cfg.PageSize = *pageSize
+	}
+
+	// Database
+	dbPath := ""
+	if *dbFlag != "" {
+		if dbPath, err = resolveDB(cfg.Profiles, *dbFlag); err != nil {
+			log.Fatalf("%s", err)
+		}
+		cfg.DBName = *dbFlag
+	}
+
+	if *wait < 0 {
+		log.Fatalf("invalid wait %s", *wait)
+	}
+
+	// the startup command runs for the UI only, before the database is
+	// opened as it may replace the file
+	var startup *startupCommandMsg
+	if cfg.StartupCommand != "" && *importPath == "" && !*printOnly && !*printStatsOnly {
+		res := runStartupCommand(cfg.StartupCommand)
+		startup = &res
+	}
+
+	db, closeDB, err := waitForDB(dbPath, *wait)
+	if errors.Is(err, errDBLocked) {
+		if *wait > 0 {
+			log.Fatalf("%s, gave up after waiting %s", err, *wait)
+		}
+		log.Fatalf("%s, close it or start tskui with --wait 30s to wait for it", err)
+	}
+	if err != nil {
+		log.Fatalf("failed to connect to BoltDB: %s", err)
+	}
+	cfg.DBPath = db.Path()
+
+	// Task repository
+	bolt, err := repository.NewBoltTaskRepository(db)
+	if err != nil {
+		log.Fatalf("failed to initialize task repository: %s", err)
+	}
+	tr := logRepository(bolt)
+
+	if *importPath != "" {
+		if cfg.ReadOnly {
+			closeDB()
+			log.Fatalf("can't import tasks in read-only mode")
+		}
+
+		imported, errs, err := importFile(tr, *importPath)
+		closeDB()
+		for _, e := range errs {
+			log.Printf("%s: %s", *importPath, e)
+		}
+		if err != nil {
+			log.Fatalf("failed to import tasks: %s", err)
+		}
+
+		fmt.Printf("imported %d tasks from %s, %d failed\n", imported, *importPath, len(errs))
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *printStatsOnly {
+		err := printStats(tr, cfg, os.Stdout)
+		closeDB()
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+		return
+	}
+
+	if *printOnly {
+		// the saved state must not change what is printed, only the archive
+		// hides tasks
+		archived := map[int]bool{}
+		if !*printArchived && cfg.StatePath != "" {
+			state, err := loadState(cfg.StatePath)
+			if err != nil {
+				log.Printf("failed to load the archive from %s: %s", cfg.StatePath, err)
+			}
+			archived = idSet(state.Archived[cfg.DBPath])
+		}
+		cfg.StatePath = ""
+		err := printTasks(tr, cfg, archived, os.Stdout)
+		closeDB()
+		if err != nil {
+			log.Fatalf("%s", err)
+		}
+		return
+	}
+
+	model := NewModel(tr, cfg)
+	model.closeDB = closeDB
+	model.startup = startup
+
+	if *serve != "" {
+		if model.server, err = startServer(*serve, tr); err != nil {
+			closeDB()
+			log.Fatalf("failed to serve the tasks: %s", err)
+		}
+	}
+
+	// nothing may be logged to the terminal while the UI runs
+	var restoreLog func()
+	if *logPath != "" {
+		if restoreLog, err = openLog(*logPath); err != nil {
+			closeDB()
+			log.Fatalf("failed to open the log: %s", err)
+		}
+		logEvent("start", "db", cfg.DBPath, "verbose", verbose)
+	} else {
+		restoreLog = silenceLog()
+	}
+
+	if cfg.RefreshOnFocus {
+		fmt.Print(enableFocusReports)
+	}
+
+	// mouse positions are relative to the terminal, the alternate screen
+	// keeps the view at its top
+	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	final, err := p.StartReturningModel()
+
+	if cfg.RefreshOnFocus {
+		fmt.Print(disableFocusReports)
+	}
+
+	if model.server != nil {
+		if err := model.server.shutdown(); err != nil {
+			log.Printf("failed to stop serving the tasks: %s", err)
+		}
+	}
+	restoreLog()
+
+	// the profile switcher may have opened another database
+	if m, ok := final.(Model); ok && m.closeDB != nil {
+		m.closeDB()
+	} else {
+		closeDB()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the clipboard failed, the copied tasks are printed instead
+	if m, ok := final.(Model); ok {
+		for _, text := range m.uncopied {
+			fmt.Print(text)
+		}
+	}
+
+	if m, ok := final.(Model); ok && *changesPath != "" {
+		if err := appendChangeLog(*changesPath, m.changes); err != nil {
+			log.Printf("failed to save the changes to %s: %s", *changesPath, err)
+		}
+	}
+
+	if m, ok := final.(Model); ok && cfg.StatePath != "" {
+		if err := saveState(cfg.StatePath, currentState(m)); err != nil {
+			log.Printf("failed to save the state to %s: %s", cfg.StatePath, err)
+		}
 	}
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// doubleClickInterval is the longest time between the clicks of a double
+// click.
+const doubleClickInterval = 500 * time.Millisecond
+
+// rowAt returns the index of the visible row shown at line y of the view.
+func rowAt(m Model, y int) (int, bool) {
+	// the rows follow the title, the top border, the header and its
+	// separator, the lines of the list the title only
+	first := titleLines + 1
+	if m.list {
+		first = titleLines
+	} else if m.tableModel.GetHeaderVisibility() {
+		first += 2
+	}
+
+	start, end := m.tableModel.VisibleIndices()
+	i := start + y - first
+	if y < first || i > end {
+		return 0, false
+	}
+
+	return i, true
+}
+
+// handleMouse highlights the clicked row and opens the details of the task on
+// a double click, the wheel moves the cursor.
+func handleMouse(m Model, msg tea.MouseMsg) Model {
+	rows := len(m.tableModel.GetVisibleRows())
+	current := m.tableModel.GetHighlightedRowIndex()
+
+	switch msg.Type {
+	case tea.MouseWheelUp:
+		if current > 0 {
+			m.tableModel = m.tableModel.WithHighlightedRow(current - 1)
+		}
+
+	case tea.MouseWheelDown:
+		if current < rows-1 {
+			m.tableModel = m.tableModel.WithHighlightedRow(current + 1)
+		}
+
+	case tea.MouseLeft:
+		if msg.X >= tableWidth(m) {
+			break
+		}
+
+		i, ok := rowAt(m, msg.Y)
+		if !ok {
+			break
+		}
+
+		m.tableModel = m.tableModel.WithHighlightedRow(i)
+
+		id, ok := highlightedTaskID(m)
+		if !ok {
+			break
+		}
+
+		now := time.Now()
+		if id == m.lastClickID && now.Sub(m.lastClickAt) <= doubleClickInterval {
+			m.lastClickID = 0
+			return showDetail(m, id)
+		}
+
+		m.lastClickID = id
+		m.lastClickAt = now
+	}
+
+	return m
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/gen2brain/beeep"
+	"github.com/kakengloh/tsk/entity"
+)
+
+// notifyFailedMsg is sent when a desktop notification could not be shown,
+// notifications are turned off then.
+type notifyFailedMsg struct {
+	err error
+}
+
+// sendNotification shows a desktop notification: through D-Bus or
+// notify-send on Linux and the BSDs, osascript on macOS and a toast on
+// Windows.
+func sendNotification(title, body string) tea.Cmd {
+	return func() tea.Msg {
+		if err := beeep.Notify(title, body, ""); err != nil {
+			return notifyFailedMsg{err: fmt.Errorf("failed to show a notification: %w", err)}
+		}
+
+		return nil
+	}
+}
+
+// notifyOverdue sends a notification for the loaded tasks that became overdue
+// since the last check, the archived ones left out. Tasks overdue before are
+// not reported, and every task only once until it is no longer overdue.
+func notifyOverdue(m Model) (Model, tea.Cmd) {
+	if !m.notifyOverdue {
+		return m, nil
+	}
+
+	now := time.Now()
+	since := m.overdueChecked
+	m.overdueChecked = now
+
+	overdue := []entity.Task{}
+	for _, task := range m.all {
+		if !isOverdue(task) || m.archived[task.ID] {
+			delete(m.notified, task.ID)
+			continue
+		}
+
+		if m.notified[task.ID] || !task.Due.After(since) {
+			continue
+		}
+
+		if m.notified == nil {
+			m.notified = map[int]bool{}
+		}
+		m.notified[task.ID] = true
+		overdue = append(overdue, task)
+	}
+
+	switch len(overdue) {
+	case 0:
+		return m, nil
+	case 1:
+		return m, sendNotification("Task overdue", fmt.Sprintf("%d: %s", overdue[0].ID, overdue[0].Title))
+	}
+
+	return m, sendNotification("Tasks overdue", fmt.Sprintf("%d tasks are overdue", len(overdue)))
+}
@@ -0,0 +1,47 @@
+package main
+
+import "strings"
+
+// minSplitWidth is the narrowest terminal the details panel is shown in, the
+// table gets the remaining two thirds of the width.
+const minSplitWidth = 90
+
+// panelShown reports whether the details panel is enabled and fits.
+func panelShown(m Model) bool {
+	return m.sidePanel && m.width >= minSplitWidth
+}
+
+// panelWidth returns the width of the details panel including its border.
+func panelWidth(m Model) int {
+	if !panelShown(m) {
+		return 0
+	}
+
+	return m.width / 3
+}
+
+// tableWidth returns the width left for the table.
+func tableWidth(m Model) int {
+	return m.width - panelWidth(m)
+}
+
+// renderSidePanel renders the details of the highlighted task, cut to height
+// lines.
+func renderSidePanel(m Model, height int) string {
+	width := panelWidth(m) - sidePanelStyle.GetHorizontalFrameSize()
+	lines := height - sidePanelStyle.GetVerticalFrameSize()
+
+	content := ""
+	if task, ok := highlightedTask(m); ok {
+		content = renderTaskFields(task, width, m.timeFormat)
+	}
+
+	if split := strings.Split(content, "\n"); lines > 0 && len(split) > lines {
+		content = strings.Join(split[:lines], "\n")
+	}
+
+	return sidePanelStyle.Copy().
+		Width(width + sidePanelStyle.GetHorizontalPadding()).
+		Height(lines).
+		Render(content)
+}
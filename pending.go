@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+)
+
+const (
+	// quitWait is how long quitting waits for the pending operations before
+	// asking whether to quit anyway.
+	quitWait = 2 * time.Second
+
+	quitPollInterval = 50 * time.Millisecond
+)
+
+// opTracker counts the repository calls in flight. The copies of the model
+// share it, the calls run in the commands.
+type opTracker struct {
+	n int64
+}
+
+func (t *opTracker) start() {
+	atomic.AddInt64(&t.n, 1)
+}
+
+func (t *opTracker) done() {
+	atomic.AddInt64(&t.n, -1)
+}
+
+func (t *opTracker) pending() int {
+	if t == nil {
+		return 0
+	}
+
+	return int(atomic.LoadInt64(&t.n))
+}
+
+// trackedRepository counts the calls of the repository it wraps while they
+// run.
+type trackedRepository struct {
+	repository.TaskRepository
+	ops *opTracker
+}
+
+// trackRepository wraps tr to count its calls in ops.
+func trackRepository(tr repository.TaskRepository, ops *opTracker) repository.TaskRepository {
+	return trackedRepository{tr, ops}
+}
+
+func (r trackedRepository) CreateTask(title string, priority entity.TaskPriority, status entity.TaskStatus, due time.Time, note string) (entity.Task, error) {
+	r.ops.start()
+	defer r.ops.done()
+
+	return r.TaskRepository.CreateTask(title, priority, status, due, note)
+}
+
+func (r trackedRepository) ListTasks(ids ...int) (entity.TaskList, error) {
+	r.ops.start()
+	defer r.ops.done()
+
+	return r.TaskRepository.ListTasks(ids...)
+}
+
+func (r trackedRepository) ListTasksWithFilters(filters entity.TaskFilters) (entity.TaskList, error) {
+	r.ops.start()
+	defer r.ops.done()
+
+	return r.TaskRepository.ListTasksWithFilters(filters)
+}
+
+func (r trackedRepository) GetTaskByID(id int) (entity.Task, error) {
+	r.ops.start()
+	defer r.ops.done()
+
+	return r.TaskRepository.GetTaskByID(id)
+}
+
+func (r trackedRepository) UpdateTask(id int, data entity.Task) (entity.Task, error) {
+	r.ops.start()
+	defer r.ops.done()
+
+	return r.TaskRepository.UpdateTask(id, data)
+}
+
+func (r trackedRepository) UpdateTaskStatus(status entity.TaskStatus, ids ...int) []repository.UpdateTaskStatusResult {
+	r.ops.start()
+	defer r.ops.done()
+
+	return r.TaskRepository.UpdateTaskStatus(status, ids...)
+}
+
+func (r trackedRepository) DeleteTask(ids ...int) error {
+	r.ops.start()
+	defer r.ops.done()
+
+	return r.TaskRepository.DeleteTask(ids...)
+}
+
+func (r trackedRepository) AddNotes(id int, notes ...string) (entity.Task, error) {
+	r.ops.start()
+	defer r.ops.done()
+
+	return r.TaskRepository.AddNotes(id, notes...)
+}
+
+// quitPollMsg is sent while quitting waits for the pending operations.
+type quitPollMsg struct{}
+
+func pollQuit() tea.Cmd {
+	return tea.Tick(quitPollInterval, func(time.Time) tea.Msg { return quitPollMsg{} })
+}
+
+// pendingOps returns the number of operations still running, a bulk update
+// counts as one between its steps.
+func pendingOps(m Model) int {
+	n := m.ops.pending()
+	if n == 0 && m.bulk.active() {
+		return 1
+	}
+
+	return n
+}
+
+// quit quits unless operations are still running, then it waits for them a
+// moment first.
+func quit(m Model) (Model, tea.Cmd) {
+	if pendingOps(m) == 0 {
+		return m, tea.Quit
+	}
+
+	m.quitAt = time.Now()
+	m = setStatus(m, fmt.Sprintf("waiting for %d pending operations, ctrl+c quits now", pendingOps(m)))
+
+	return m, pollQuit()
+}
+
+// quitPolled quits once the pending operations are done. If they take too
+// long it asks whether to quit anyway.
+func quitPolled(m Model) (Model, tea.Cmd) {
+	if m.quitAt.IsZero() {
+		return m, nil
+	}
+
+	n := pendingOps(m)
+	if n == 0 {
+		return m, tea.Quit
+	}
+	if time.Since(m.quitAt) < quitWait {
+		return m, pollQuit()
+	}
+
+	m.quitAt = time.Time{}
+	m = setStatus(m, "")
+	m.mode = modeConfirm
+	m.confirm = newConfirmDialog(fmt.Sprintf("%d operations are still running and may be lost, quit anyway?", n), tea.Quit)
+
+	return m, nil
+}
+
+// updateQuitting handles the keys while quitting waits: ctrl+c quits right
+// away, esc stays.
+func updateQuitting(m Model, msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.quitAt = time.Time{}
+		m = setStatus(m, "")
+	}
+
+	return m, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// columnsChangedMsg is sent whenever a column is shown, hidden or resized.
+type columnsChangedMsg struct {
+	hidden map[string]bool
+	widths map[string]int
+}
+
+// columnPickerDoneMsg is sent when the column picker is closed, resized tells
+// whether the widths have changed.
+type columnPickerDoneMsg struct {
+	hidden  map[string]bool
+	widths  map[string]int
+	resized bool
+}
+
+// columnPicker lists the columns of the table, toggles their visibility and
+// resizes them. At least one column always stays visible.
+type columnPicker struct {
+	cursor     int
+	hidden     map[string]bool
+	widths     map[string]int
+	titleWidth int // the width of the title while it shares the free space
+	resized    bool
+}
+
+func newColumnPicker(hidden map[string]bool, widths map[string]int, titleWidth int) columnPicker {
+	copied := make(map[string]bool, len(hidden))
+	for column, h := range hidden {
+		copied[column] = h
+	}
+
+	return columnPicker{hidden: copied, widths: copyWidths(widths), titleWidth: titleWidth}
+}
+
+func copyWidths(widths map[string]int) map[string]int {
+	copied := make(map[string]int, len(widths))
+	for column, w := range widths {
+		copied[column] = w
+	}
+
+	return copied
+}
+
+// resize widens or narrows the column under the cursor by delta. The title
+// gets fixed at its current width first.
+func (p columnPicker) resize(delta int) (columnPicker, tea.Cmd) {
+	column := columnOrder[p.cursor]
+
+	w, ok := p.widths[column]
+	if !ok {
+		w = p.titleWidth
+	}
+	if w+delta < minColumnWidth {
+		return p, nil
+	}
+
+	p.widths = copyWidths(p.widths)
+	p.widths[column] = w + delta
+	p.resized = true
+
+	hidden, widths := p.hidden, p.widths
+	return p, func() tea.Msg { return columnsChangedMsg{hidden: hidden, widths: widths} }
+}
+
+func (p columnPicker) visibleCount() int {
+	count := 0
+	for _, column := range columnOrder {
+		if !p.hidden[column] {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (p columnPicker) Update(msg tea.Msg) (columnPicker, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q", "enter":
+		msg := columnPickerDoneMsg{hidden: p.hidden, widths: p.widths, resized: p.resized}
+		return p, func() tea.Msg { return msg }
+
+	case "j", "down":
+		if p.cursor < len(columnOrder)-1 {
+			p.cursor++
+		}
+
+	case "k", "up":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+
+	case " ", "x":
+		column := columnOrder[p.cursor]
+		if !p.hidden[column] && p.visibleCount() == 1 {
+			return p, nil
+		}
+
+		p.hidden = newColumnPicker(p.hidden, nil, 0).hidden
+		p.hidden[column] = !p.hidden[column]
+
+		hidden, widths := p.hidden, p.widths
+		return p, func() tea.Msg { return columnsChangedMsg{hidden: hidden, widths: widths} }
+
+	case "<":
+		return p.resize(-1)
+
+	case ">":
+		return p.resize(1)
+	}
+
+	return p, nil
+}
+
+func (p columnPicker) View() string {
+	b := strings.Builder{}
+
+	b.WriteString(promptLabelStyle.Render("Columns"))
+	b.WriteString("\n")
+
+	for i, column := range columnOrder {
+		cursor := "  "
+		if i == p.cursor {
+			cursor = "> "
+		}
+
+		check := "[x] "
+		if p.hidden[column] {
+			check = "[ ] "
+		}
+
+		title := columnTitles[column]
+		if column == columnKeyIcon {
+			// the icon column has no header
+			title = "Icons"
+		}
+
+		width := "auto"
+		if w, ok := p.widths[column]; ok {
+			width = fmt.Sprint(w)
+		}
+
+		line := cursor + check + padRight(title, 10) + " " + width
+		if i == p.cursor {
+			line = helpKeyStyle.Render(line)
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString(helpHintStyle.Render("space: show/hide · </>: narrow/widen · esc: close"))
+
+	return b.String()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// pinKey is the hidden row data key sorting the pinned tasks first.
+	pinKey = "pin"
+
+	pinPrefix = "📌 "
+)
+
+// pinRank sorts the pinned tasks before the others.
+func pinRank(pinned bool) int {
+	if pinned {
+		return 0
+	}
+
+	return 1
+}
+
+func idSet(ids []int) map[int]bool {
+	set := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	return set
+}
+
+// idsByDB returns the task IDs of every database in byDB, the ones of the
+// database at path replaced by current.
+func idsByDB(byDB map[string][]int, path string, current map[int]bool) map[string][]int {
+	all := make(map[string][]int, len(byDB)+1)
+	for p, ids := range byDB {
+		all[p] = ids
+	}
+
+	ids := []int{}
+	for id := range current {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	if len(ids) > 0 {
+		all[path] = ids
+	} else {
+		delete(all, path)
+	}
+
+	return all
+}
+
+// allPins returns the pinned task IDs of every database, the ones of the open
+// database as currently pinned.
+func allPins(m Model) map[string][]int {
+	return idsByDB(m.pins, m.dbPath, m.pinned)
+}
+
+// togglePin pins or unpins the highlighted task. The pins are saved to the
+// state file right away, they are not meant to be lost with a crash.
+func togglePin(m Model) (Model, tea.Cmd) {
+	id, ok := highlightedTaskID(m)
+	if !ok {
+		return m, nil
+	}
+
+	pinned := make(map[int]bool, len(m.pinned)+1)
+	for pin := range m.pinned {
+		pinned[pin] = true
+	}
+
+	status := fmt.Sprintf("pinned task %d", id)
+	if pinned[id] {
+		delete(pinned, id)
+		status = fmt.Sprintf("unpinned task %d", id)
+	} else {
+		pinned[id] = true
+	}
+
+	m.pinned = pinned
+	m = highlightTask(updateRows(m), id)
+
+	if m.statePath != "" {
+		if err := saveState(m.statePath, currentState(m)); err != nil {
+			return setError(m, fmt.Errorf("failed to save the pins: %w", err), nil), nil
+		}
+	}
+
+	return flashStatus(m, status)
+}
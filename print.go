@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kakengloh/tsk/repository"
+	"golang.org/x/term"
+)
+
+// defaultPrintWidth is the width of the printed table when stdout is not a
+// terminal.
+const defaultPrintWidth = 100
+
+// printTasks writes the table of the tasks to w once, without starting the
+// UI. The archived tasks are left out.
+func printTasks(tr repository.TaskRepository, cfg config, archived map[int]bool, w io.Writer) error {
+	m := NewModel(tr, cfg)
+
+	tasks, err := tr.ListTasksWithFilters(m.filters)
+	if err != nil {
+		return fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	m.all = tasks
+	m.archived = archived
+	m.width = defaultPrintWidth
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		m.width = width
+	}
+
+	m = updateRows(applyColumns(m))
+	m.tableModel = m.tableModel.Focused(false).SelectableRows(false)
+
+	_, err = fmt.Fprintln(w, m.tableModel.View())
+	return err
+}
+
+// printStats writes the stats of every task of the database to w, the
+// filters don't apply.
+func printStats(tr repository.TaskRepository, cfg config, w io.Writer) error {
+	applyTheme(cfg.Theme)
+	applyTimeLocale(cfg.TimeLocale)
+
+	tasks, err := tr.ListTasks()
+	if err != nil {
+		return fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, renderTaskStats(computeTaskStats(tasks, time.Now()), time.Now()))
+	return err
+}
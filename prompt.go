@@ -0,0 +1,114 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptDoneMsg is sent when the input prompt is closed.
+type promptDoneMsg struct{}
+
+// inputPrompt is a single line text input. On enter the value is passed to
+// submit; if it returns an error the prompt stays open and shows the error.
+type inputPrompt struct {
+	label   string
+	initial string
+	input   textinput.Model
+	err     string
+	submit  func(value string) (tea.Cmd, error)
+	change  func(value string) tea.Cmd
+	cancel  tea.Cmd
+	repeat  bool
+}
+
+func newInputPrompt(label, value string, submit func(value string) (tea.Cmd, error)) inputPrompt {
+	input := textinput.New()
+	input.Prompt = ""
+	input.SetValue(value)
+	input.CursorEnd()
+	input.Focus()
+
+	return inputPrompt{
+		label:   label,
+		initial: value,
+		input:   input,
+		submit:  submit,
+	}
+}
+
+// withChange sets a callback run whenever the value is edited.
+func (p inputPrompt) withChange(change func(value string) tea.Cmd) inputPrompt {
+	p.change = change
+
+	return p
+}
+
+// withCancel sets a command run when the prompt is dismissed with escape.
+func (p inputPrompt) withCancel(cancel tea.Cmd) inputPrompt {
+	p.cancel = cancel
+
+	return p
+}
+
+// withRepeat keeps the prompt open after a successful submit, with the input
+// cleared for the next value.
+func (p inputPrompt) withRepeat() inputPrompt {
+	p.repeat = true
+
+	return p
+}
+
+// dirty reports whether the value has been edited and not submitted yet.
+// Prompts with a change callback apply their value while it is typed, so they
+// are never dirty.
+func (p inputPrompt) dirty() bool {
+	return p.change == nil && p.input.Value() != p.initial
+}
+
+func (p inputPrompt) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (p inputPrompt) Update(msg tea.Msg) (inputPrompt, tea.Cmd) {
+	done := func() tea.Msg { return promptDoneMsg{} }
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc":
+			return p, tea.Batch(done, p.cancel)
+
+		case "enter":
+			cmd, err := p.submit(p.input.Value())
+			if err != nil {
+				p.err = err.Error()
+				return p, nil
+			}
+			if p.repeat {
+				p.err = ""
+				p.input.SetValue("")
+				return p, cmd
+			}
+			return p, tea.Batch(done, cmd)
+		}
+	}
+
+	var cmd tea.Cmd
+	value := p.input.Value()
+	p.input, cmd = p.input.Update(msg)
+
+	if p.change != nil && p.input.Value() != value {
+		cmd = tea.Batch(cmd, p.change(p.input.Value()))
+	}
+
+	return p, cmd
+}
+
+func (p inputPrompt) View() string {
+	view := promptLabelStyle.Render(p.label+": ") + p.input.View()
+
+	if p.err != "" {
+		view += "\n" + promptErrorStyle.Render(p.err)
+	}
+
+	return view
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const flashDuration = 2 * time.Second
+
+// clearStatusMsg clears the status line if it still shows status.
+type clearStatusMsg struct {
+	status string
+}
+
+// flashStatus sets a status message that disappears after a short while.
+func flashStatus(m Model, status string) (Model, tea.Cmd) {
+	return setStatus(m, status), tea.Tick(flashDuration, func(time.Time) tea.Msg {
+		return clearStatusMsg{status: status}
+	})
+}
+
+// elapsedInterval is how often the time spent on tasks in progress is shown
+// again.
+const elapsedInterval = time.Minute
+
+// elapsedTickMsg is sent periodically to render the elapsed times again.
+type elapsedTickMsg time.Time
+
+func elapsedTick() tea.Cmd {
+	return tea.Tick(elapsedInterval, func(t time.Time) tea.Msg {
+		return elapsedTickMsg(t)
+	})
+}
+
+// refreshTickMsg is sent periodically to check the database for changes.
+type refreshTickMsg time.Time
+
+func refreshTick(interval time.Duration) tea.Cmd {
+	if interval <= 0 {
+		return nil
+	}
+
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return refreshTickMsg(t)
+	})
+}
+
+// dbModTime returns the modification time of the database file, or the zero
+// time if it can't be determined.
+func dbModTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
+
+// refreshIfChanged reloads the tasks when the database file has been modified
+// since the last check.
+func refreshIfChanged(m Model) (Model, tea.Cmd) {
+	modTime := dbModTime(m.dbPath)
+	if modTime.IsZero() || modTime.Equal(m.dbModTime) {
+		return m, nil
+	}
+
+	m.dbModTime = modTime
+
+	return refresh(m)
+}
+
+// refresh reloads the tasks and keeps the highlighted task if it is still
+// listed.
+func refresh(m Model) (Model, tea.Cmd) {
+	return loadTasks(m, false)
+}
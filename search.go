@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// The search matches are emphasized with bold and underline. Only these
+// attributes are switched off after a match, a full reset would also clear
+// the colors of the highlighted row for the rest of the title.
+const (
+	matchStart = "\x1b[1;4m"
+	matchEnd   = "\x1b[22;24m"
+)
+
+// highlightMatches emphasizes every case-insensitive occurrence of the search
+// in the title.
+func highlightMatches(title, search string) string {
+	query := strings.ToLower(strings.TrimPrefix(search, noteSearchPrefix))
+	lower := strings.ToLower(title)
+
+	// the offsets of lower only apply to title if lowering kept the lengths
+	if query == "" || len(lower) != len(title) || lipgloss.ColorProfile() == termenv.Ascii {
+		return title
+	}
+
+	b := strings.Builder{}
+	start := 0
+
+	for {
+		i := strings.Index(lower[start:], query)
+		if i < 0 {
+			break
+		}
+
+		i += start
+		b.WriteString(title[start:i])
+		b.WriteString(matchStart)
+		b.WriteString(title[i : i+len(query)])
+		b.WriteString(matchEnd)
+		start = i + len(query)
+	}
+
+	b.WriteString(title[start:])
+
+	return b.String()
+}
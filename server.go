@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+)
+
+// serverShutdownTimeout is how long the requests being served are waited for
+// on quit.
+const serverShutdownTimeout = 2 * time.Second
+
+// taskServer serves the tasks as JSON while the UI runs. It is read-only and
+// follows the database opened by the UI.
+type taskServer struct {
+	mu  sync.RWMutex
+	tr  repository.TaskRepository
+	srv *http.Server
+}
+
+// startServer starts serving the tasks of tr on addr, e.g. ":8080". The
+// address is bound before returning so a busy port is reported right away.
+func startServer(addr string, tr repository.TaskRepository) (*taskServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &taskServer{tr: tr}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tasks", s.handleTasks)
+	s.srv = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("failed to serve the tasks: %s", err)
+		}
+	}()
+
+	return s, nil
+}
+
+// setRepository switches to the repository of another database.
+func (s *taskServer) setRepository(tr repository.TaskRepository) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tr = tr
+}
+
+// shutdown stops the server, waiting a bit for the requests being served.
+func (s *taskServer) shutdown() error {
+	ctx, cancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	defer cancel()
+
+	return s.srv.Shutdown(ctx)
+}
+
+// handleTasks lists every task of the database, in the format of the JSON
+// export.
+func (s *taskServer) handleTasks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// the read lock keeps the database from being switched, and closed,
+	// while it is read
+	s.mu.RLock()
+	tasks, err := s.tr.ListTasksWithFilters(entity.TaskFilters{})
+	s.mu.RUnlock()
+	if err != nil {
+		http.Error(w, "failed to list tasks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(exportedTasks(tasks)); err != nil {
+		log.Printf("failed to send the tasks: %s", err)
+	}
+}
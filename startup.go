@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startupCommandTimeout is how long the startup command may run before it is
+// stopped.
+const startupCommandTimeout = 30 * time.Second
+
+// startupCommandMsg is the outcome of the startup command, shown once the UI
+// has started.
+type startupCommandMsg struct {
+	output string
+	err    error
+}
+
+// shellCommand returns the command running line with the shell of the
+// platform.
+func shellCommand(ctx context.Context, line string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", line)
+	}
+
+	return exec.CommandContext(ctx, "sh", "-c", line)
+}
+
+// runStartupCommand runs the startup command of the config file. It runs
+// before the database is opened, so that it may replace the file.
+func runStartupCommand(line string) startupCommandMsg {
+	ctx, cancel := context.WithTimeout(context.Background(), startupCommandTimeout)
+	defer cancel()
+
+	out, err := shellCommand(ctx, line).CombinedOutput()
+	output := strings.TrimSpace(string(out))
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("stopped after %s", startupCommandTimeout)
+	}
+	if err != nil {
+		if output != "" {
+			err = fmt.Errorf("%w: %s", err, lastLine(output))
+		}
+		return startupCommandMsg{output: output, err: fmt.Errorf("startup command failed: %w", err)}
+	}
+
+	return startupCommandMsg{output: output}
+}
+
+// lastLine returns the last line of the output, the one a command usually
+// sums up in.
+func lastLine(output string) string {
+	lines := strings.Split(output, "\n")
+
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// startupCommandDone shows the last line of the output of the startup command
+// for a moment, or its error until it is dismissed. The whole output is
+// logged.
+func startupCommandDone(m Model, msg startupCommandMsg) (Model, tea.Cmd) {
+	logEvent("startup_command", "output", msg.output, "failed", msg.err != nil)
+
+	if msg.err != nil {
+		return setError(m, msg.err, nil), nil
+	}
+	if msg.output == "" {
+		return m, nil
+	}
+
+	return flashStatus(m, "startup command: "+lastLine(msg.output))
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// uiState is saved on quit and restored on the next start.
+type uiState struct {
+	HighlightedID int    `json:"highlighted_id,omitempty"`
+	SortColumn    string `json:"sort_column,omitempty"`
+	SortDesc      bool   `json:"sort_desc,omitempty"`
+	SidePanel     bool   `json:"side_panel,omitempty"`
+	Grouping      string `json:"grouping,omitempty"`
+	Dense         bool   `json:"dense,omitempty"`
+	List          bool   `json:"list,omitempty"`
+
+	// Pinned holds the pinned task IDs by database path.
+	Pinned map[string][]int `json:"pinned,omitempty"`
+	// Archived holds the archived task IDs by database path.
+	Archived map[string][]int `json:"archived,omitempty"`
+}
+
+// stateFilePath returns the path of the state file in $XDG_STATE_HOME, or in
+// ~/.local/state if it is not set.
+func stateFilePath() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(dir, "tskui", "state.json"), nil
+}
+
+// loadState reads the saved state, a missing file gives the zero state.
+func loadState(path string) (uiState, error) {
+	state := uiState{}
+
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+
+	if err := json.Unmarshal(buf, &state); err != nil {
+		return uiState{}, err
+	}
+
+	return state, nil
+}
+
+func saveState(path string, state uiState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	buf, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(buf, '\n'), 0644)
+}
+
+// currentState returns the state of m to be saved.
+func currentState(m Model) uiState {
+	state := uiState{}
+
+	if id, ok := highlightedTaskID(m); ok {
+		state.HighlightedID = id
+	}
+
+	state.SortColumn = m.sortColumn
+	state.SortDesc = m.sortDesc
+	state.SidePanel = m.sidePanel
+	state.Grouping = groupingNames[m.grouping]
+	state.Dense = m.dense
+	state.List = m.list
+	state.Pinned = allPins(m)
+	state.Archived = allArchived(m)
+
+	return state
+}
+
+// restoreState applies a saved state to m, unknown values are ignored.
+func restoreState(m Model, state uiState) Model {
+	m.restoreID = state.HighlightedID
+
+	if validSortColumn(state.SortColumn) {
+		m.sortColumn = state.SortColumn
+		m.sortDesc = state.SortDesc
+	}
+
+	m.sidePanel = state.SidePanel
+	for g, name := range groupingNames {
+		if name == state.Grouping {
+			m.grouping = g
+		}
+	}
+	m.dense = state.Dense
+	m.list = state.List
+	m.pins = state.Pinned
+	m.pinned = idSet(state.Pinned[m.dbPath])
+	m.archive = state.Archived
+	m.archived = idSet(state.Archived[m.dbPath])
+
+	return m
+}
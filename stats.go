@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kakengloh/tsk/entity"
+)
+
+// statsDays is the number of days the sparkline of the stats panel covers.
+const statsDays = 14
+
+// statsPanelLines is the height of the stats panel, its border included.
+const statsPanelLines = 3
+
+// sparkBlocks are the bars of the sparkline, from the lowest to the highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// createdPerDay counts the tasks created on each of the last days, the
+// oldest day first and today last.
+func createdPerDay(tasks []entity.Task, days int, now time.Time) []int {
+	counts := make([]int, days)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	first := today.AddDate(0, 0, -(days - 1))
+
+	for _, task := range tasks {
+		created := task.CreatedAt.In(now.Location())
+		if created.Before(first) || !created.Before(today.AddDate(0, 0, 1)) {
+			continue
+		}
+
+		day := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, now.Location())
+		// days are counted by date, a day may not be 24 hours long
+		i := int(day.Sub(first).Hours()/24 + 0.5)
+		if i >= 0 && i < days {
+			counts[i]++
+		}
+	}
+
+	return counts
+}
+
+// sparkline renders the counts as a line of blocks scaled to the highest
+// count. Only days without tasks get the lowest block.
+func sparkline(counts []int) string {
+	most := 0
+	for _, count := range counts {
+		if count > most {
+			most = count
+		}
+	}
+
+	b := strings.Builder{}
+	for _, count := range counts {
+		level := 0
+		if count > 0 {
+			level = 1 + (count*(len(sparkBlocks)-1)-1)/most
+		}
+		b.WriteRune(sparkBlocks[level])
+	}
+
+	return b.String()
+}
+
+// renderStatsPanel renders the number of listed tasks created on each of the
+// last days.
+func renderStatsPanel(m Model) string {
+	counts := createdPerDay(m.tasks, statsDays, time.Now())
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	line := fmt.Sprintf("%s %s  %d today · %d in %d days",
+		detailLabelStyle.Render("Created"),
+		statusStyle.Render(sparkline(counts)),
+		counts[len(counts)-1], total, statsDays)
+
+	width := m.width - sidePanelStyle.GetHorizontalFrameSize()
+	if width < 0 {
+		width = 0
+	}
+
+	return sidePanelStyle.Copy().
+		Width(width + sidePanelStyle.GetHorizontalPadding()).
+		MaxWidth(m.width).
+		Render(line)
+}
+
+// taskStats are the numbers printed by --stats, of every task of the
+// database.
+type taskStats struct {
+	total       int
+	byStatus    map[entity.TaskStatus]int
+	byPriority  map[entity.TaskPriority]int
+	overdue     int
+	dueThisWeek int // the unfinished tasks due from now to the end of the week
+	open        int
+	openAge     time.Duration // the ages of the unfinished tasks added up
+}
+
+func computeTaskStats(tasks []entity.Task, now time.Time) taskStats {
+	s := taskStats{
+		total:      len(tasks),
+		byStatus:   map[entity.TaskStatus]int{},
+		byPriority: map[entity.TaskPriority]int{},
+	}
+
+	for _, task := range tasks {
+		s.byStatus[task.Status]++
+		s.byPriority[task.Priority]++
+
+		if task.Status == entity.TaskStatusDone {
+			continue
+		}
+
+		s.open++
+		s.openAge += now.Sub(task.CreatedAt)
+
+		switch dueBucketOf(task, now) {
+		case bucketOverdue:
+			s.overdue++
+		case bucketToday, bucketThisWeek:
+			s.dueThisWeek++
+		}
+	}
+
+	return s
+}
+
+// capitalize upper-cases the first letter of the names of tsk.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// renderTaskStats renders the stats as a panel of labels and numbers.
+func renderTaskStats(s taskStats, now time.Time) string {
+	label := detailLabelStyle.Copy().Width(15)
+	b := strings.Builder{}
+
+	line := func(name string, value interface{}) {
+		b.WriteString(label.Render(name))
+		b.WriteString(detailValueStyle.Render(fmt.Sprint(value)))
+		b.WriteString("\n")
+	}
+
+	b.WriteString(detailTitleStyle.Render("Task statistics"))
+	b.WriteString("\n\n")
+
+	line("Total", s.total)
+	b.WriteString("\n")
+
+	for _, status := range []entity.TaskStatus{entity.TaskStatusTodo, entity.TaskStatusDoing, entity.TaskStatusDone} {
+		line(capitalize(entity.TaskStatusToString[status]), s.byStatus[status])
+	}
+	b.WriteString("\n")
+
+	for _, priority := range []entity.TaskPriority{entity.TaskPriorityHigh, entity.TaskPriorityMedium, entity.TaskPriorityLow} {
+		line(capitalize(entity.TaskPriorityToString[priority]), s.byPriority[priority])
+	}
+	if n := s.byPriority[entity.TaskPriorityNone]; n > 0 {
+		line("No priority", n)
+	}
+	b.WriteString("\n")
+
+	line("Overdue", s.overdue)
+	line("Due this week", s.dueThisWeek)
+
+	age := "-"
+	if s.open > 0 {
+		age = elapsedFormat.Format(now.Add(-s.openAge / time.Duration(s.open)))
+	}
+	b.WriteString(label.Render("Average age"))
+	b.WriteString(detailValueStyle.Render(age))
+	b.WriteString(detailHelpStyle.Render(" of the unfinished tasks"))
+
+	return sidePanelStyle.Render(b.String())
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kakengloh/tsk/entity"
+)
+
+// tagPattern matches the #tags used in task titles and notes.
+var tagPattern = regexp.MustCompile(`(^|\s)#([\p{L}\p{N}_-]+)`)
+
+// taskTags returns the lower-cased tags found in the title and notes of the
+// task, without the leading #.
+func taskTags(task entity.Task) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+
+	for _, text := range append([]string{task.Title}, task.Notes...) {
+		for _, match := range tagPattern.FindAllStringSubmatch(text, -1) {
+			tag := strings.ToLower(match[2])
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	return tags
+}
+
+func hasTag(task entity.Task, tag string) bool {
+	for _, t := range taskTags(task) {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// collectTags returns the sorted tags of all the tasks.
+func collectTags(tasks []entity.Task) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+
+	for _, task := range tasks {
+		for _, tag := range taskTags(task) {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	sort.Strings(tags)
+
+	return tags
+}
+
+// nextTag returns the tag after current in tags, or no tag after the last one.
+func nextTag(tags []string, current string) string {
+	if current == "" {
+		if len(tags) > 0 {
+			return tags[0]
+		}
+		return ""
+	}
+
+	for i, tag := range tags {
+		if tag == current && i+1 < len(tags) {
+			return tags[i+1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// the sequences turning the focus reports of the terminal on and off, see
+// "FocusIn/FocusOut" in the xterm control sequences
+const (
+	enableFocusReports  = "\x1b[?1004h"
+	disableFocusReports = "\x1b[?1004l"
+)
+
+// focusReport tells whether the key is a focus report of the terminal, and
+// whether the terminal gained the focus. Bubble Tea doesn't know the reports,
+// "ESC [ I" and "ESC [ O" arrive as the keys alt+[I and alt+[O.
+func focusReport(msg tea.KeyMsg) (gained bool, ok bool) {
+	if !msg.Alt || msg.Type != tea.KeyRunes {
+		return false, false
+	}
+
+	switch string(msg.Runes) {
+	case "[I":
+		return true, true
+	case "[O":
+		return false, true
+	}
+
+	return false, false
+}
+
+// terminalFocused reloads the tasks when the terminal gets the focus back, to
+// show the changes made meanwhile without waiting for the next check.
+func terminalFocused(m Model) (Model, tea.Cmd) {
+	m.dbModTime = dbModTime(m.dbPath)
+
+	return refresh(m)
+}
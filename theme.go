@@ -0,0 +1,293 @@
+package main
+
+import (
+	"log"
+	"regexp"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kakengloh/tsk/entity"
+)
+
+// Theme holds the colors of the UI. Every color is either a hex value (#rgb or
+// #rrggbb) or an ANSI color number (0-255). Symbols marks overdue and soon due
+// tasks with a symbol too, not only by color.
+type Theme struct {
+	Heading             string `json:"heading"`
+	Border              string `json:"border"`
+	Text                string `json:"text"`
+	Light               string `json:"light"`
+	Highlight           string `json:"highlight"`
+	HighlightBackground string `json:"highlight_background"`
+	Overdue             string `json:"overdue"`
+	DueSoon             string `json:"due_soon"`
+	Error               string `json:"error"`
+	ErrorText           string `json:"error_text"`
+	PriorityLow         string `json:"priority_low"`
+	PriorityMedium      string `json:"priority_medium"`
+	PriorityHigh        string `json:"priority_high"`
+	StatusTodo          string `json:"status_todo"`
+	StatusDoing         string `json:"status_doing"`
+	StatusDone          string `json:"status_done"`
+	Symbols             bool   `json:"symbols"`
+}
+
+func defaultTheme() Theme {
+	return Theme{
+		Heading:             "#83a598",
+		Border:              "#689d6a",
+		Text:                "#b8bb26",
+		Light:               "#ebdbb2",
+		Highlight:           "#fabd2f",
+		HighlightBackground: "#3c3836",
+		Overdue:             "#cc241d",
+		DueSoon:             "#fe8019",
+		Error:               "#cc241d",
+		ErrorText:           "#fbf1c7",
+		PriorityLow:         "#8ec07c",
+		PriorityMedium:      "#fe8019",
+		PriorityHigh:        "#fb4934",
+		StatusTodo:          "#a89984",
+		StatusDoing:         "#fabd2f",
+		StatusDone:          "#b8bb26",
+	}
+}
+
+// colorblindTheme uses the Okabe-Ito palette, which stays distinguishable with
+// the common forms of color blindness, and marks the due dates with symbols.
+func colorblindTheme() Theme {
+	return Theme{
+		Heading:             "#56b4e9",
+		Border:              "#0072b2",
+		Text:                "#f0f0f0",
+		Light:               "#cccccc",
+		Highlight:           "#f0e442",
+		HighlightBackground: "#303030",
+		Overdue:             "#d55e00",
+		DueSoon:             "#e69f00",
+		Error:               "#d55e00",
+		ErrorText:           "#000000",
+		PriorityLow:         "#009e73",
+		PriorityMedium:      "#e69f00",
+		PriorityHigh:        "#cc79a7",
+		StatusTodo:          "#cccccc",
+		StatusDoing:         "#56b4e9",
+		StatusDone:          "#009e73",
+		Symbols:             true,
+	}
+}
+
+const defaultThemePreset = "default"
+
+// themePresetNames lists the built-in themes, in the order they are cycled
+// through.
+var themePresetNames = []string{defaultThemePreset, "colorblind"}
+
+func themePreset(name string) (Theme, bool) {
+	switch name {
+	case defaultThemePreset:
+		return defaultTheme(), true
+	case "colorblind":
+		return colorblindTheme(), true
+	}
+
+	return Theme{}, false
+}
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+func validColor(color string) bool {
+	if hexColorPattern.MatchString(color) {
+		return true
+	}
+
+	n, err := strconv.Atoi(color)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// validateTheme replaces the invalid colors of t with the ones of defaults,
+// reporting each of them.
+func validateTheme(t Theme, defaults Theme) Theme {
+	colors := []struct {
+		name     string
+		value    *string
+		fallback string
+	}{
+		{"heading", &t.Heading, defaults.Heading},
+		{"border", &t.Border, defaults.Border},
+		{"text", &t.Text, defaults.Text},
+		{"light", &t.Light, defaults.Light},
+		{"highlight", &t.Highlight, defaults.Highlight},
+		{"highlight_background", &t.HighlightBackground, defaults.HighlightBackground},
+		{"overdue", &t.Overdue, defaults.Overdue},
+		{"due_soon", &t.DueSoon, defaults.DueSoon},
+		{"error", &t.Error, defaults.Error},
+		{"error_text", &t.ErrorText, defaults.ErrorText},
+		{"priority_low", &t.PriorityLow, defaults.PriorityLow},
+		{"priority_medium", &t.PriorityMedium, defaults.PriorityMedium},
+		{"priority_high", &t.PriorityHigh, defaults.PriorityHigh},
+		{"status_todo", &t.StatusTodo, defaults.StatusTodo},
+		{"status_doing", &t.StatusDoing, defaults.StatusDoing},
+		{"status_done", &t.StatusDone, defaults.StatusDone},
+	}
+
+	for _, color := range colors {
+		if !validColor(*color.value) {
+			log.Printf("invalid %s color %q in theme, using %q", color.name, *color.value, color.fallback)
+			*color.value = color.fallback
+		}
+	}
+
+	return t
+}
+
+// The colored styles of the UI, set by applyTheme.
+var (
+	statusStyle         lipgloss.Style
+	titleStyle          lipgloss.Style
+	errorBannerStyle    lipgloss.Style
+	overdueRowStyle     lipgloss.Style
+	dueSoonStyle        lipgloss.Style
+	tableHeaderStyle    lipgloss.Style
+	tableBaseStyle      lipgloss.Style
+	tableHighlightStyle lipgloss.Style
+	idColumnStyle       lipgloss.Style
+	sidePanelStyle      lipgloss.Style
+	groupHeaderStyle    lipgloss.Style
+	focusStyle          lipgloss.Style
+
+	confirmStyle lipgloss.Style
+
+	detailTitleStyle lipgloss.Style
+	detailLabelStyle lipgloss.Style
+	detailValueStyle lipgloss.Style
+	detailNoteStyle  lipgloss.Style
+
+	formTitleStyle lipgloss.Style
+	formLabelStyle lipgloss.Style
+	formErrorStyle lipgloss.Style
+
+	helpTitleStyle lipgloss.Style
+	helpKeyStyle   lipgloss.Style
+	helpDescStyle  lipgloss.Style
+
+	promptLabelStyle lipgloss.Style
+	promptErrorStyle lipgloss.Style
+
+	priorityStyles map[entity.TaskPriority]lipgloss.Style
+	statusStyles   map[entity.TaskStatus]lipgloss.Style
+
+	// overduePrefix and dueSoonPrefix mark the tasks when the theme uses
+	// symbols.
+	overduePrefix string
+	dueSoonPrefix string
+)
+
+func applyTheme(t Theme) {
+	heading := lipgloss.Color(t.Heading)
+	border := lipgloss.Color(t.Border)
+	text := lipgloss.Color(t.Text)
+	light := lipgloss.Color(t.Light)
+	highlight := lipgloss.Color(t.Highlight)
+	errorColor := lipgloss.Color(t.Error)
+
+	statusStyle = lipgloss.NewStyle().Foreground(heading)
+	titleStyle = lipgloss.NewStyle().Foreground(heading).Bold(true)
+	errorBannerStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color(t.ErrorText)).
+		Background(errorColor).
+		Padding(0, 1)
+	overdueRowStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Overdue))
+	dueSoonStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.DueSoon))
+	tableHeaderStyle = lipgloss.NewStyle().Foreground(heading).Bold(true)
+	tableBaseStyle = lipgloss.NewStyle().
+		BorderForeground(border).
+		Foreground(text).
+		Align(lipgloss.Left)
+	tableHighlightStyle = lipgloss.NewStyle().
+		Foreground(highlight).
+		Background(lipgloss.Color(t.HighlightBackground))
+	idColumnStyle = lipgloss.NewStyle().
+		Faint(true).
+		Foreground(highlight).
+		Align(lipgloss.Center)
+
+	groupHeaderStyle = lipgloss.NewStyle().Foreground(heading).Bold(true)
+	sidePanelStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(border).
+		Padding(0, 1)
+
+	focusStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(highlight).
+		Padding(1, 3)
+
+	confirmStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(highlight).
+		Foreground(highlight).
+		Padding(0, 1)
+
+	detailTitleStyle = lipgloss.NewStyle().Foreground(highlight).Bold(true)
+	detailLabelStyle = lipgloss.NewStyle().Foreground(heading).Width(10)
+	detailValueStyle = lipgloss.NewStyle().Foreground(text)
+	detailNoteStyle = lipgloss.NewStyle().Foreground(light)
+
+	formTitleStyle = lipgloss.NewStyle().Foreground(heading).Bold(true)
+	formLabelStyle = lipgloss.NewStyle().Foreground(border).Width(10)
+	formErrorStyle = lipgloss.NewStyle().Foreground(errorColor)
+
+	helpTitleStyle = lipgloss.NewStyle().Foreground(heading).Bold(true)
+	helpKeyStyle = lipgloss.NewStyle().Foreground(highlight)
+	helpDescStyle = lipgloss.NewStyle().Foreground(light)
+
+	promptLabelStyle = lipgloss.NewStyle().Foreground(heading).Bold(true)
+	promptErrorStyle = lipgloss.NewStyle().Foreground(errorColor)
+
+	priorityStyles = map[entity.TaskPriority]lipgloss.Style{
+		entity.TaskPriorityLow:    lipgloss.NewStyle().Foreground(lipgloss.Color(t.PriorityLow)),
+		entity.TaskPriorityMedium: lipgloss.NewStyle().Foreground(lipgloss.Color(t.PriorityMedium)),
+		entity.TaskPriorityHigh:   lipgloss.NewStyle().Foreground(lipgloss.Color(t.PriorityHigh)),
+	}
+
+	statusStyles = map[entity.TaskStatus]lipgloss.Style{
+		entity.TaskStatusTodo:  lipgloss.NewStyle().Foreground(lipgloss.Color(t.StatusTodo)),
+		entity.TaskStatusDoing: lipgloss.NewStyle().Foreground(lipgloss.Color(t.StatusDoing)),
+		entity.TaskStatusDone:  lipgloss.NewStyle().Foreground(lipgloss.Color(t.StatusDone)),
+	}
+
+	overduePrefix, dueSoonPrefix = "", ""
+	if t.Symbols {
+		overduePrefix, dueSoonPrefix = "! ", "~ "
+	}
+}
+
+// nextThemePreset switches to the built-in theme after the current one, the
+// preset chosen in the config file keeps the colors set there.
+func nextThemePreset(m Model) Model {
+	name := themePresetNames[0]
+	for i, preset := range themePresetNames {
+		if preset == m.themePreset {
+			name = themePresetNames[(i+1)%len(themePresetNames)]
+			break
+		}
+	}
+
+	t, _ := themePreset(name)
+	if name == m.configPreset {
+		t = m.configTheme
+	}
+	m.themePreset = name
+
+	applyTheme(t)
+
+	m.tableModel = m.tableModel.
+		HeaderStyle(tableHeaderStyle).
+		WithBaseStyle(tableBaseStyle).
+		HighlightStyle(tableHighlightStyle)
+	m.spinner = newSpinner()
+	m.progress = newProgress(t)
+
+	return updateRows(applyColumns(m))
+}
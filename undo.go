@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kakengloh/tsk/entity"
+	"github.com/kakengloh/tsk/repository"
+)
+
+// maxUndo is the number of operations that can be undone.
+const maxUndo = 10
+
+// undoEntry holds what is needed to reverse an operation: the deleted tasks
+// to recreate and the previous statuses of the changed tasks.
+type undoEntry struct {
+	description string
+	deleted     []entity.Task
+	statuses    map[int]entity.TaskStatus
+}
+
+// undoneMsg is sent after an operation has been undone, restored maps the IDs
+// of the recreated tasks to their new IDs.
+type undoneMsg struct {
+	description string
+	restored    map[int]int
+}
+
+// pushUndo records an undoable operation, dropping the oldest one when the
+// stack is full. Empty entries are ignored.
+func pushUndo(m Model, entry undoEntry) Model {
+	if entry.description == "" {
+		return m
+	}
+
+	m.undo = append(m.undo, entry)
+	if len(m.undo) > maxUndo {
+		m.undo = m.undo[len(m.undo)-maxUndo:]
+	}
+
+	return m
+}
+
+// remapUndo updates the recorded operations to use the new IDs of recreated
+// tasks.
+func remapUndo(m Model, restored map[int]int) Model {
+	for _, entry := range m.undo {
+		for oldID, newID := range restored {
+			if status, ok := entry.statuses[oldID]; ok {
+				delete(entry.statuses, oldID)
+				entry.statuses[newID] = status
+			}
+		}
+	}
+
+	return m
+}
+
+// undoOperation reverses entry. It is not retryable, as retrying after a
+// partial failure would recreate some of the tasks twice.
+func undoOperation(tr repository.TaskRepository, entry undoEntry) tea.Cmd {
+	return func() tea.Msg {
+		restored := map[int]int{}
+
+		for _, task := range entry.deleted {
+			id, err := restoreTask(tr, task)
+			if err != nil {
+				return errMsg{err: fmt.Errorf("failed to undo %s: %w", entry.description, err)}
+			}
+			restored[task.ID] = id
+		}
+
+		for id, status := range entry.statuses {
+			for _, res := range tr.UpdateTaskStatus(status, id) {
+				if res.Err != nil {
+					return errMsg{err: fmt.Errorf("failed to undo %s: %w", entry.description, res.Err)}
+				}
+			}
+		}
+
+		return undoneMsg{description: entry.description, restored: restored}
+	}
+}
+
+// restoreTask recreates a deleted task with all its notes and returns its new
+// ID, the repository assigns a new ID and creation time.
+func restoreTask(tr repository.TaskRepository, task entity.Task) (int, error) {
+	note := ""
+	if len(task.Notes) > 0 {
+		note = task.Notes[0]
+	}
+
+	created, err := tr.CreateTask(task.Title, task.Priority, task.Status, task.Due, note)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(task.Notes) > 1 {
+		if _, err := tr.AddNotes(created.ID, task.Notes[1:]...); err != nil {
+			return created.ID, err
+		}
+	}
+
+	return created.ID, nil
+}